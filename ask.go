@@ -0,0 +1,164 @@
+package telebot
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrAskTimeout is returned by Ask when there is no answer in time.
+	ErrAskTimeout = errors.New("telebot: no answer in time")
+
+	// ErrAskCanceled is returned by Ask when another question is
+	// asked of the same user in the chat, or the bot is stopped.
+	ErrAskCanceled = errors.New("telebot: question is canceled")
+
+	// ErrAskSynchronous is returned by Ask with the Synchronous bot,
+	// which can't receive the answer while the handler is waiting.
+	ErrAskSynchronous = errors.New("telebot: can't ask in synchronous mode")
+)
+
+// AskTimeout limits the time Ask waits for the answer.
+// By default, it waits until the bot is stopped.
+type AskTimeout time.Duration
+
+type askKey struct {
+	chatID, userID int64
+}
+
+type asker struct {
+	msgID  int
+	answer chan Context
+	cancel chan struct{}
+}
+
+// askers are the handlers waiting for the answers.
+type askers struct {
+	mu      sync.Mutex
+	waiting map[askKey]*asker
+}
+
+// add adds the waiting handler, the previous one
+// waiting for the same user in the chat is canceled.
+func (a *askers) add(key askKey) *asker {
+	w := &asker{
+		answer: make(chan Context, 1),
+		cancel: make(chan struct{}),
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.waiting == nil {
+		a.waiting = make(map[askKey]*asker)
+	}
+	if prev, ok := a.waiting[key]; ok {
+		close(prev.cancel)
+	}
+	a.waiting[key] = w
+	return w
+}
+
+// asked sets the ID of the message with the question, so the
+// callbacks of its buttons are taken as the answer.
+func (a *askers) asked(w *asker, msgID int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	w.msgID = msgID
+}
+
+func (a *askers) remove(key askKey, w *asker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.waiting[key] == w {
+		delete(a.waiting, key)
+	}
+}
+
+// deliver passes the update to the handler waiting for it, if any.
+// The update is taken as the answer if it's the message of the user
+// in the chat, or the callback of the question's buttons.
+func (a *askers) deliver(c Context) bool {
+	u := c.Update()
+	if u.Message == nil && u.Callback == nil {
+		return false
+	}
+
+	chat, sender := c.Chat(), c.Sender()
+	if chat == nil || sender == nil {
+		return false
+	}
+	key := askKey{chatID: chat.ID, userID: sender.ID}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.waiting[key]
+	if !ok {
+		return false
+	}
+	if u.Callback != nil && (u.Callback.Message == nil || u.Callback.Message.ID != w.msgID) {
+		return false
+	}
+
+	delete(a.waiting, key)
+	w.answer <- c
+	return true
+}
+
+// ask sends the question to the chat of the context and waits for the
+// answer of the same user. The answer is not handled by the handlers.
+func (b *Bot) ask(c Context, what interface{}, opts ...interface{}) (Context, error) {
+	if b.synchronous {
+		return nil, ErrAskSynchronous
+	}
+
+	chat, sender := c.Chat(), c.Sender()
+	if chat == nil || sender == nil {
+		return nil, ErrBadContext
+	}
+
+	var timeout time.Duration
+	sendOpts := opts[:0:0]
+	for _, opt := range opts {
+		if d, ok := opt.(AskTimeout); ok {
+			timeout = time.Duration(d)
+		} else {
+			sendOpts = append(sendOpts, opt)
+		}
+	}
+
+	// waiting before the question is sent,
+	// so the quick answer is not missed
+	key := askKey{chatID: chat.ID, userID: sender.ID}
+	w := b.askers.add(key)
+	defer b.askers.remove(key, w)
+
+	msg, err := b.Send(chat, what, sendOpts...)
+	if err != nil {
+		return nil, err
+	}
+	b.askers.asked(w, msg.ID)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case answer := <-w.answer:
+		return answer, nil
+	case <-w.cancel:
+		return nil, ErrAskCanceled
+	case <-expired:
+		return nil, ErrAskTimeout
+	case <-b.Context().Done():
+		return nil, b.Context().Err()
+	case <-b.stopClient.done():
+		return nil, ErrAskCanceled
+	}
+}
@@ -0,0 +1,160 @@
+package telebot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAskBot(t *testing.T) *Bot {
+	var msgID int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := atomic.AddInt32(&msgID, 1)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":` + strconv.Itoa(int(id)) + `,"chat":{"id":1}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+	return b
+}
+
+func TestAsk(t *testing.T) {
+	b := newAskBot(t)
+
+	chat := &Chat{ID: 1}
+	alice, bob := &User{ID: 1}, &User{ID: 2}
+
+	type result struct {
+		answer Context
+		err    error
+	}
+	results := make(chan result, 1)
+	texts := make(chan string, 10)
+
+	b.Handle("/signup", func(c Context) error {
+		answer, err := c.Ask("What's your email?", AskTimeout(time.Second))
+		results <- result{answer, err}
+		return nil
+	})
+	b.Handle(OnText, func(c Context) error {
+		texts <- c.Text()
+		return nil
+	})
+	b.Handle(OnCallback, func(c Context) error {
+		texts <- "callback"
+		return nil
+	})
+
+	waitAsked := func() {
+		require.Eventually(t, func() bool {
+			b.askers.mu.Lock()
+			defer b.askers.mu.Unlock()
+			for _, w := range b.askers.waiting {
+				if w.msgID != 0 {
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+	}
+
+	t.Run("Message", func(t *testing.T) {
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "/signup"}})
+		waitAsked()
+
+		// other users and chats are handled as usual
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: bob, Text: "bob"}})
+		b.ProcessUpdate(Update{Message: &Message{Chat: &Chat{ID: 2}, Sender: alice, Text: "other"}})
+		// the callbacks of the other messages as well
+		b.ProcessUpdate(Update{Callback: &Callback{
+			Sender:  alice,
+			Message: &Message{ID: 100, Chat: chat},
+		}})
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "alice@example.com"}})
+
+		r := <-results
+		require.NoError(t, r.err)
+		assert.Equal(t, "alice@example.com", r.answer.Text())
+
+		assert.ElementsMatch(t, []string{"bob", "other", "callback"}, []string{<-texts, <-texts, <-texts})
+		assert.Empty(t, texts)
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "/signup"}})
+		waitAsked()
+
+		b.askers.mu.Lock()
+		msgID := b.askers.waiting[askKey{chatID: 1, userID: 1}].msgID
+		b.askers.mu.Unlock()
+
+		b.ProcessUpdate(Update{Callback: &Callback{
+			Sender:  alice,
+			Message: &Message{ID: msgID, Chat: chat},
+			Data:    "yes",
+		}})
+
+		r := <-results
+		require.NoError(t, r.err)
+		assert.Equal(t, "yes", r.answer.Callback().Data)
+		assert.Empty(t, texts)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		b.Handle("/quick", func(c Context) error {
+			answer, err := c.Ask("Quick!", AskTimeout(10*time.Millisecond))
+			results <- result{answer, err}
+			return nil
+		})
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "/quick"}})
+
+		r := <-results
+		assert.Equal(t, ErrAskTimeout, r.err)
+
+		// the waiter is released
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "late"}})
+		assert.Equal(t, "late", <-texts)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		b.Handle(&InlineButton{Unique: "ask"}, func(c Context) error {
+			answer, err := c.Ask("What's your phone?")
+			results <- result{answer, err}
+			return nil
+		})
+
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "/signup"}})
+		waitAsked()
+
+		// the button of another message asks again
+		b.ProcessUpdate(Update{Callback: &Callback{
+			Sender:  alice,
+			Message: &Message{ID: 100, Chat: chat},
+			Data:    "\fask",
+		}})
+
+		r := <-results
+		assert.Equal(t, ErrAskCanceled, r.err)
+
+		b.ProcessUpdate(Update{Message: &Message{Chat: chat, Sender: alice, Text: "answer"}})
+		r = <-results
+		require.NoError(t, r.err)
+		assert.Equal(t, "answer", r.answer.Text())
+	})
+}
+
+func TestAskSynchronous(t *testing.T) {
+	b, err := NewBot(Settings{Offline: true, Synchronous: true})
+	require.NoError(t, err)
+
+	c := b.NewContext(Update{Message: &Message{Chat: &Chat{ID: 1}, Sender: &User{ID: 1}}})
+	_, err = c.Ask("What's your email?")
+	assert.Equal(t, ErrAskSynchronous, err)
+}
@@ -1,7 +1,9 @@
 package telebot
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -44,12 +46,26 @@ func NewBot(pref Settings) (*Bot, error) {
 
 		Updates:  make(chan Update, pref.Updates),
 		handlers: make(map[string]HandlerFunc),
+		chains:   make(map[string]HandlerFunc),
 		stop:     make(chan chan struct{}),
 
+		stopClient: &stopSignal{},
+		running:    &runningGroup{},
+		tracker:    &updateTracker{},
+		replies:    &webhookReplies{},
+		askers:     &askers{},
+
+		interceptors: pref.Interceptors,
+
 		synchronous: pref.Synchronous,
 		verbose:     pref.Verbose,
+		local:       pref.Local,
 		parseMode:   pref.ParseMode,
 		client:      client,
+		limiter:     pref.Limiter,
+		retry:       pref.Retry,
+		fileCache:   pref.FileCache,
+		workers:     pref.Workers,
 	}
 
 	if pref.Offline {
@@ -77,14 +93,64 @@ type Bot struct {
 
 	group       *Group
 	handlers    map[string]HandlerFunc
+	chains      map[string]HandlerFunc // handlers without bot middleware
+	patterns    []patternHandler
 	synchronous bool
 	verbose     bool
+	local       bool
 	parseMode   ParseMode
 	stop        chan chan struct{}
 	client      *http.Client
+	limiter     *Limiter
+	retry       *RetryPolicy
+	fileCache   FileCache
+	reupload    bool
+	workers     *WorkerPool
+	ctx         context.Context
+	stopClient  *stopSignal
+	running     *runningGroup
+	tracker     *updateTracker
+	replies     *webhookReplies
+	replyTo     *int
+	askers      *askers
+
+	interceptors []Interceptor
+}
+
+// stopSignal is closed when the bot is about to stop, so all
+// the pending requests get cancelled. It is shared between
+// the bot and its copies made by WithContext.
+type stopSignal struct {
+	mu sync.RWMutex
+	ch chan struct{}
+}
+
+// open makes a new signal, it returns false if already opened.
+func (s *stopSignal) open() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ch != nil {
+		return false
+	}
+	s.ch = make(chan struct{})
+	return true
+}
+
+func (s *stopSignal) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ch != nil {
+		close(s.ch)
+		s.ch = nil
+	}
+}
 
-	stopMu     sync.RWMutex
-	stopClient chan struct{}
+func (s *stopSignal) done() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ch
 }
 
 // Settings represents a utility struct for passing certain
@@ -103,6 +169,11 @@ type Settings struct {
 	// It makes ProcessUpdate return after the handler is finished.
 	Synchronous bool
 
+	// Workers runs the handlers in a bounded pool of goroutines,
+	// keeping the updates of one chat in order. Ignored if the
+	// bot is Synchronous. See WorkerPool for details.
+	Workers *WorkerPool
+
 	// Verbose forces bot to log all upcoming requests.
 	// Use for debugging purposes only.
 	Verbose bool
@@ -122,6 +193,29 @@ type Settings struct {
 
 	// Offline allows to create a bot without network for testing purposes.
 	Offline bool
+
+	// Interceptors wrap every request made by the bot.
+	// See Interceptor for details.
+	Interceptors []Interceptor
+
+	// Limiter schedules outgoing messages to keep the bot within
+	// Telegram limits. See Limiter for details.
+	Limiter *Limiter
+
+	// Retry retries the requests failed with transient errors.
+	// See RetryPolicy for details.
+	Retry *RetryPolicy
+
+	// FileCache stores the IDs of the uploaded files, so they are
+	// not uploaded again. See FileCache for details.
+	FileCache FileCache
+
+	// Local is used with a self-hosted Bot API server running in the
+	// --local mode on the same file system as the bot. The files on
+	// disk are then passed by their paths instead of being uploaded,
+	// and the downloaded ones are read right from the server's disk.
+	// See https://github.com/tdlib/telegram-bot-api.
+	Local bool
 }
 
 var defaultOnError = func(err error, c Context) {
@@ -174,19 +268,36 @@ var (
 // Middleware usage:
 //
 //	b.Handle("/ban", onBan, middleware.Whitelist(ids...))
+//
+// The handler replaces the one set for the endpoint before, unless
+// it returns ErrSkip: then the update is passed to the previous one,
+// and the bot middleware isn't applied again. It lets the middleware
+// guard the handlers of the same endpoint:
+//
+//	b.Handle(tele.OnText, onText)
+//	b.Handle(tele.OnText, onPhone, fsm.In("await_phone"))
 func (b *Bot) Handle(endpoint interface{}, h HandlerFunc, m ...MiddlewareFunc) {
 	end := extractEndpoint(endpoint)
 	if end == "" {
 		panic("telebot: unsupported endpoint")
 	}
 
-	if len(b.group.middleware) > 0 {
-		m = appendMiddleware(b.group.middleware, m)
+	// The handlers are chained without the bot middleware,
+	// so it runs once however many of them skip the update.
+	prev := b.chains[end]
+	chain := func(c Context) error {
+		err := applyMiddleware(h, m...)(c)
+		if !errors.Is(err, ErrSkip) {
+			return err
+		}
+		if prev != nil {
+			return prev(c)
+		}
+		return nil
 	}
 
-	b.handlers[end] = func(c Context) error {
-		return applyMiddleware(h, m...)(c)
-	}
+	b.chains[end] = chain
+	b.handlers[end] = applyMiddleware(chain, b.group.middleware...)
 }
 
 // Trigger executes the registered handler by the endpoint.
@@ -212,15 +323,10 @@ func (b *Bot) Start() {
 	}
 
 	// do nothing if called twice
-	b.stopMu.Lock()
-	if b.stopClient != nil {
-		b.stopMu.Unlock()
+	if !b.stopClient.open() {
 		return
 	}
 
-	b.stopClient = make(chan struct{})
-	b.stopMu.Unlock()
-
 	stop := make(chan struct{})
 	stopConfirm := make(chan struct{})
 
@@ -246,18 +352,123 @@ func (b *Bot) Start() {
 
 // Stop gracefully shuts the poller down.
 func (b *Bot) Stop() {
-	b.stopMu.Lock()
-	if b.stopClient != nil {
-		close(b.stopClient)
-		b.stopClient = nil
-	}
-	b.stopMu.Unlock()
+	b.stopClient.close()
 
 	confirm := make(chan struct{})
 	b.stop <- confirm
 	<-confirm
 }
 
+// Shutdown gracefully shuts the bot down. Unlike Stop, it doesn't
+// cancel the pending requests right away: the poller is stopped first,
+// then Shutdown waits for the running handlers to finish. If the context
+// expires before that, the remaining requests are cancelled and the
+// context error is returned.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//
+//	if err := b.Shutdown(ctx); err != nil {
+//		log.Println("some handlers are still running:", err)
+//	}
+func (b *Bot) Shutdown(ctx context.Context) error {
+	defer b.stopClient.close()
+
+	if b.stopClient.done() != nil {
+		confirm := make(chan struct{})
+		b.stop <- confirm
+
+		select {
+		case <-confirm:
+		case <-ctx.Done():
+			// the poller may wait for the requests in progress,
+			// e.g. the webhook ones, so they are cancelled
+			b.stopClient.close()
+			<-confirm
+			return ctx.Err()
+		}
+	}
+
+	return b.running.wait(ctx)
+}
+
+// runningGroup counts the handlers currently running.
+type runningGroup struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{}
+}
+
+func (g *runningGroup) add() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.n == 0 {
+		g.idle = make(chan struct{})
+	}
+	g.n++
+}
+
+func (g *runningGroup) done() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.n--
+	if g.n == 0 {
+		close(g.idle)
+	}
+}
+
+// wait blocks until there are no running handlers or ctx is done.
+func (g *runningGroup) wait(ctx context.Context) error {
+	g.mu.Lock()
+	if g.n == 0 {
+		g.mu.Unlock()
+		return nil
+	}
+	idle := g.idle
+	g.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithContext returns a shallow copy of the bot with its context
+// changed to ctx. Every request made through the copy is bound to
+// the context and gets aborted with its error as soon as ctx is done.
+// The requests are still cancelled when the original bot stops.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//
+//	_, err := b.WithContext(ctx).Send(to, "Hello!")
+func (b *Bot) WithContext(ctx context.Context) *Bot {
+	if ctx == nil {
+		panic("telebot: nil context")
+	}
+
+	b2 := *b
+	b2.ctx = ctx
+	return &b2
+}
+
+// Context returns the bot's context. To change it, use WithContext.
+// The returned context is always non-nil, it defaults to the background one.
+func (b *Bot) Context() context.Context {
+	if b.ctx != nil {
+		return b.ctx
+	}
+	return context.Background()
+}
+
 // NewMarkup simply returns newly created markup instance.
 func (b *Bot) NewMarkup() *ReplyMarkup {
 	return &ReplyMarkup{}
@@ -283,12 +494,14 @@ func (b *Bot) NewContext(u Update) Context {
 //   - *ReplyMarkup (a component of SendOptions)
 //   - Option (a shortcut flag for popular options)
 //   - ParseMode (HTML, Markdown, etc)
+//   - ProgressFunc (reports the upload progress)
 func (b *Bot) Send(to Recipient, what interface{}, opts ...interface{}) (*Message, error) {
 	if to == nil {
 		return nil, ErrBadRecipient
 	}
 
 	sendOpts := b.extractOptions(opts)
+	b = b.withProgress(sendOpts)
 
 	switch object := what.(type) {
 	case string:
@@ -312,6 +525,7 @@ func (b *Bot) SendPaid(to Recipient, stars int, a PaidAlbum, opts ...interface{}
 		"star_count": strconv.Itoa(stars),
 	}
 	sendOpts := b.extractOptions(opts)
+	b = b.withProgress(sendOpts)
 
 	media := make([]string, len(a))
 	files := make(map[string]File)
@@ -356,11 +570,23 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...interface{}) ([]Message,
 	}
 
 	sendOpts := b.extractOptions(opts)
+	b = b.withProgress(sendOpts)
+
 	media := make([]string, len(a))
 	files := make(map[string]File)
 
+	// keys of the uploaded files, and the ones sent from the cache
+	keys, hits := make([]string, len(a)), []string(nil)
+
 	for i, x := range a {
-		repr := x.MediaFile().process(strconv.Itoa(i), files)
+		f, key := b.cachedFile(x.MediaType(), x.MediaFile())
+		if f.FileID != "" && key != "" {
+			hits = append(hits, key)
+		} else {
+			keys[i] = key
+		}
+
+		repr := f.process(strconv.Itoa(i), files)
 		if repr == "" {
 			return nil, fmt.Errorf("telebot: album entry #%d does not exist", i)
 		}
@@ -384,7 +610,17 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...interface{}) ([]Message,
 	}
 	b.embedSendOptions(params, sendOpts)
 
+	rewind := rewindFiles(files)
 	data, err := b.sendFiles("sendMediaGroup", files, params)
+	if len(hits) > 0 && isWrongFileID(err) {
+		for _, key := range hits {
+			b.forgetFile(key)
+		}
+		if err := rewind(); err != nil {
+			return nil, wrapError(err)
+		}
+		return b.reuploading().SendAlbum(to, a, opts...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -396,6 +632,11 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...interface{}) ([]Message,
 		return nil, wrapError(err)
 	}
 
+	// the messages are unknown when sent as a webhook reply
+	if len(resp.Result) == 0 {
+		return nil, nil
+	}
+
 	for attachName := range files {
 		i, _ := strconv.Atoi(attachName)
 		r := resp.Result[i]
@@ -415,6 +656,12 @@ func (b *Bot) SendAlbum(to Recipient, a Album, opts ...interface{}) ([]Message,
 		a[i].MediaFile().FileID = newID
 	}
 
+	for i, key := range keys {
+		if key != "" && i < len(resp.Result) {
+			b.rememberFile(key, resp.Result[i].Media())
+		}
+	}
+
 	return resp.Result, nil
 }
 
@@ -653,28 +900,29 @@ func (b *Bot) EditCaption(msg Editable, caption string, opts ...interface{}) (*M
 func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...interface{}) (*Message, error) {
 	var (
 		repr  string
-		file  = media.MediaFile()
 		files = make(map[string]File)
 
 		thumb     *Photo
 		thumbName = "thumb"
 	)
 
+	file, key := b.cachedFile(media.MediaType(), media.MediaFile())
+
 	switch {
 	case file.InCloud():
 		repr = file.FileID
 	case file.FileURL != "":
 		repr = file.FileURL
-	case file.OnDisk() || file.FileReader != nil:
+	case file.OnDisk() || file.FileOpener != nil || file.FileReader != nil:
 		s := file.FileLocal
-		if file.FileReader != nil {
+		if !file.OnDisk() {
 			s = "0"
 		} else if s == thumbName {
 			thumbName = "thumb2"
 		}
 
 		repr = "attach://" + s
-		files[s] = *file
+		files[s] = file
 	default:
 		return nil, fmt.Errorf("telebot: cannot edit media, it does not exist")
 	}
@@ -695,6 +943,7 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...interface{}) (*M
 
 	sendOpts := b.extractOptions(opts)
 	b.embedSendOptions(params, sendOpts)
+	b = b.withProgress(sendOpts)
 
 	im := media.InputMedia()
 	im.Media = repr
@@ -720,12 +969,28 @@ func (b *Bot) EditMedia(msg Editable, media Inputtable, opts ...interface{}) (*M
 		params["message_id"] = msgID
 	}
 
+	rewind := rewindFiles(files)
 	data, err := b.sendFiles("editMessageMedia", files, params)
+	if file.FileID != "" && key != "" && isWrongFileID(err) {
+		b.forgetFile(key)
+		if err := rewind(); err != nil {
+			return nil, wrapError(err)
+		}
+		return b.reuploading().EditMedia(msg, media, opts...)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return extractMessage(data)
+	edited, err := extractMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.FileID == "" && key != "" && edited != nil {
+		b.rememberFile(key, edited.Media())
+	}
+	return edited, nil
 }
 
 // Delete removes the message, including service messages.
@@ -927,7 +1192,8 @@ func (b *Bot) FileByID(fileID string) (File, error) {
 }
 
 // Download saves the file from Telegram servers locally.
-// Maximum file size to download is 20 MB.
+// Maximum file size to download is 20 MB, or 2000 MB in the local mode.
+// See DownloadTo to limit the size or stream the file elsewhere.
 func (b *Bot) Download(file *File, localFilename string) error {
 	reader, err := b.File(file)
 	if err != nil {
@@ -952,30 +1218,21 @@ func (b *Bot) Download(file *File, localFilename string) error {
 
 // File gets a file from Telegram servers.
 func (b *Bot) File(file *File) (io.ReadCloser, error) {
+	if file.FileSize > b.DownloadLimit() {
+		return nil, ErrFileTooBig
+	}
+
 	f, err := b.FileByID(file.FileID)
 	if err != nil {
 		return nil, err
 	}
-
-	url := b.URL + "/file/bot" + b.Token + "/" + f.FilePath
 	file.FilePath = f.FilePath // saving file path
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, wrapError(err)
-	}
-
-	resp, err := b.client.Do(req)
+	body, size, err := b.openFile(f, 0)
 	if err != nil {
-		return nil, wrapError(err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, fmt.Errorf("telebot: expected status 200 but got %s", resp.Status)
+		return nil, err
 	}
-
-	return resp.Body, nil
+	return file.trackDownload(body, 0, size), nil
 }
 
 // StopLiveLocation stops broadcasting live message location
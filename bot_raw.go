@@ -20,6 +20,47 @@ import (
 // It also handles API errors, so you only need to unwrap
 // result field from json data.
 func (b *Bot) Raw(method string, payload interface{}) ([]byte, error) {
+	return b.intercepted(&Request{Method: method, Params: payload})
+}
+
+func (b *Bot) sendFiles(method string, files map[string]File, params map[string]string) ([]byte, error) {
+	return b.intercepted(&Request{Method: method, Params: params, Files: files})
+}
+
+// request is the innermost RequestFunc, which actually makes the request.
+func (b *Bot) request(req *Request) ([]byte, error) {
+	if req.Context != nil && req.Context != b.Context() {
+		b = b.WithContext(req.Context)
+	}
+
+	if req.Files == nil {
+		return b.retried(nil, func() ([]byte, error) {
+			return b.post(req.Method, req.Params)
+		})
+	}
+
+	params, ok := req.Params.(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("telebot: params of %s with files should be map[string]string", req.Method)
+	}
+	return b.retried(req.Files, func() ([]byte, error) {
+		return b.postFiles(req.Method, req.Files, params)
+	})
+}
+
+func (b *Bot) post(method string, payload interface{}) ([]byte, error) {
+	if b.replyTo != nil {
+		if data, ok := b.replies.reply(*b.replyTo, method, payload); ok {
+			return data, nil
+		}
+	}
+
+	return b.limited(method, payload, true, func() ([]byte, error) {
+		return b.raw(method, payload)
+	})
+}
+
+func (b *Bot) raw(method string, payload interface{}) ([]byte, error) {
 	url := b.URL + "/bot" + b.Token + "/" + method
 
 	var buf bytes.Buffer
@@ -27,24 +68,9 @@ func (b *Bot) Raw(method string, payload interface{}) ([]byte, error) {
 		return nil, err
 	}
 
-	// Cancel the request immediately without waiting for the timeout
-	// when bot is about to stop.
-	// This may become important if doing long polling with long timeout.
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := b.requestContext()
 	defer cancel()
 
-	go func() {
-		b.stopMu.RLock()
-		stopCh := b.stopClient
-		b.stopMu.RUnlock()
-
-		select {
-		case <-stopCh:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
 	if err != nil {
 		return nil, wrapError(err)
@@ -67,39 +93,85 @@ func (b *Bot) Raw(method string, payload interface{}) ([]byte, error) {
 		verbose(method, payload, data)
 	}
 
+	if err := statusError(resp.StatusCode); err != nil {
+		return nil, err
+	}
+
 	// returning data as well
 	return data, extractOk(data)
 }
 
-func (b *Bot) sendFiles(method string, files map[string]File, params map[string]string) ([]byte, error) {
+func (b *Bot) postFiles(method string, files map[string]File, params map[string]string) ([]byte, error) {
 	rawFiles := make(map[string]interface{})
+	localFiles := make(map[string]string)
 	for name, f := range files {
 		switch {
 		case f.InCloud():
 			params[name] = f.FileID
 		case f.FileURL != "":
 			params[name] = f.FileURL
+		case b.tooBig(&f):
+			return nil, ErrFileTooBig
+		case f.OnDisk() && b.local:
+			uri, err := fileURI(f.FileLocal)
+			if err != nil {
+				return nil, wrapError(err)
+			}
+			params[name] = uri
+			localFiles[name] = uri
 		case f.OnDisk():
 			rawFiles[name] = f.FileLocal
+		case f.FileOpener != nil:
+			rawFiles[name] = f.FileOpener
 		case f.FileReader != nil:
 			rawFiles[name] = f.FileReader
 		default:
 			return nil, fmt.Errorf("telebot: file for field %s doesn't exist", name)
 		}
 	}
+	attachLocal(params, localFiles)
 
 	if len(rawFiles) == 0 {
-		return b.Raw(method, params)
+		return b.post(method, params)
 	}
 
+	// Consumed readers can't be sent again by the limiter,
+	// the retry policy rewinds them before retrying, if it can.
+	return b.limited(method, params, false, func() ([]byte, error) {
+		return b.sendMultipart(method, files, rawFiles, params)
+	})
+}
+
+func (b *Bot) sendMultipart(method string, files map[string]File, rawFiles map[string]interface{}, params map[string]string) ([]byte, error) {
 	pipeReader, pipeWriter := io.Pipe()
 	writer := multipart.NewWriter(pipeWriter)
 
+	// The files must not be read anymore once the request
+	// is over, so they can be rewound and sent again.
+	written := make(chan struct{})
+	defer func() {
+		pipeReader.Close()
+		<-written
+	}()
+
+	var total int64
+	for field, file := range rawFiles {
+		if size := uploadSize(files[field], file); size >= 0 && total >= 0 {
+			total += size
+		} else {
+			total = -1
+		}
+	}
+	overall := newProgress(progressFrom(b.Context()), total)
+
 	go func() {
+		defer close(written)
 		defer pipeWriter.Close()
 
 		for field, file := range rawFiles {
-			if err := addFileToWriter(writer, files[field].fileName, field, file); err != nil {
+			f := files[field]
+			ps := []*progress{overall, newProgress(f.OnProgress, uploadSize(f, file))}
+			if err := addFileToWriter(writer, f.fileName, field, file, ps...); err != nil {
 				pipeWriter.CloseWithError(err)
 				return
 			}
@@ -118,7 +190,18 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 
 	url := b.URL + "/bot" + b.Token + "/" + method
 
-	resp, err := b.client.Post(url, writer.FormDataContentType(), pipeReader)
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pipeReader)
+	if err != nil {
+		err = wrapError(err)
+		pipeReader.CloseWithError(err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := b.client.Do(req)
 	if err != nil {
 		err = wrapError(err)
 		pipeReader.CloseWithError(err)
@@ -127,8 +210,8 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 	resp.Close = true
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusInternalServerError {
-		return nil, ErrInternal
+	if err := statusError(resp.StatusCode); err != nil {
+		return nil, err
 	}
 
 	data, err := ioutil.ReadAll(resp.Body)
@@ -139,10 +222,49 @@ func (b *Bot) sendFiles(method string, files map[string]File, params map[string]
 	return data, extractOk(data)
 }
 
-func addFileToWriter(writer *multipart.Writer, filename, field string, file interface{}) error {
+// requestContext returns a context for a single request derived from
+// the bot's one. It cancels the request immediately without waiting
+// for the timeout when bot is about to stop.
+// This may become important if doing long polling with long timeout.
+func (b *Bot) requestContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(b.Context())
+
+	go func() {
+		select {
+		case <-b.stopClient.done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// statusError returns the error of a server failed with 5xx status.
+func statusError(code int) error {
+	switch {
+	case code == http.StatusInternalServerError:
+		return ErrInternal
+	case code > http.StatusInternalServerError:
+		return NewError(code, http.StatusText(code))
+	default:
+		return nil
+	}
+}
+
+func addFileToWriter(writer *multipart.Writer, filename, field string, file interface{}, ps ...*progress) error {
 	var reader io.Reader
 	if r, ok := file.(io.Reader); ok {
 		reader = r
+	} else if open, ok := file.(func() (io.Reader, error)); ok {
+		r, err := open()
+		if err != nil {
+			return err
+		}
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		reader = r
 	} else if path, ok := file.(string); ok {
 		f, err := os.Open(path)
 		if err != nil {
@@ -159,7 +281,7 @@ func addFileToWriter(writer *multipart.Writer, filename, field string, file inte
 		return err
 	}
 
-	_, err = io.Copy(part, reader)
+	_, err = io.Copy(progressWriter{w: part, ps: ps}, reader)
 	return err
 }
 
@@ -169,7 +291,7 @@ func (f *File) process(name string, files map[string]File) string {
 		return f.FileID
 	case f.FileURL != "":
 		return f.FileURL
-	case f.OnDisk() || f.FileReader != nil:
+	case f.OnDisk() || f.FileOpener != nil || f.FileReader != nil:
 		files[name] = *f
 		return "attach://" + name
 	}
@@ -191,6 +313,8 @@ func (b *Bot) sendText(to Recipient, text string, opt *SendOptions) (*Message, e
 	return extractMessage(data)
 }
 
+// sendMedia sends the media, the returned message is nil
+// when it's sent as a webhook reply.
 func (b *Bot) sendMedia(media Media, params map[string]string, files map[string]File) (*Message, error) {
 	kind := media.MediaType()
 	what := "send" + strings.Title(kind)
@@ -199,32 +323,40 @@ func (b *Bot) sendMedia(media Media, params map[string]string, files map[string]
 		kind = "video_note"
 	}
 
-	sendFiles := map[string]File{kind: *media.MediaFile()}
+	file, key := b.cachedFile(media.MediaType(), media.MediaFile())
+
+	sendFiles := map[string]File{kind: file}
 	for k, v := range files {
 		sendFiles[k] = v
 	}
 
+	rewind := rewindFiles(sendFiles)
 	data, err := b.sendFiles(what, sendFiles, params)
+	if file.FileID != "" && key != "" && isWrongFileID(err) {
+		b.forgetFile(key)
+		delete(params, kind)
+		if err := rewind(); err != nil {
+			return nil, wrapError(err)
+		}
+		return b.reuploading().sendMedia(media, params, files)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return extractMessage(data)
-}
-
-func (b *Bot) getMe() (*User, error) {
-	data, err := b.Raw("getMe", nil)
+	msg, err := extractMessage(data)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp struct {
-		Result *User
+	if file.FileID == "" && key != "" && msg != nil {
+		b.rememberFile(key, msg.Media())
 	}
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, wrapError(err)
-	}
-	return resp.Result, nil
+	return msg, nil
+}
+
+func (b *Bot) getMe() (*User, error) {
+	return Call[*User](b, "getMe", nil)
 }
 
 func (b *Bot) getUpdates(offset, limit int, timeout time.Duration, allowed []string) ([]Update, error) {
@@ -240,18 +372,7 @@ func (b *Bot) getUpdates(offset, limit int, timeout time.Duration, allowed []str
 		params["limit"] = strconv.Itoa(limit)
 	}
 
-	data, err := b.Raw("getUpdates", params)
-	if err != nil {
-		return nil, err
-	}
-
-	var resp struct {
-		Result []Update
-	}
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, wrapError(err)
-	}
-	return resp.Result, nil
+	return Call[[]Update](b, "getUpdates", params)
 }
 
 func (b *Bot) forwardCopyMany(to Recipient, msgs []Editable, key string, opts ...*SendOptions) ([]Message, error) {
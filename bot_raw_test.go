@@ -1,6 +1,7 @@
 package telebot
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -75,6 +77,40 @@ func TestRaw(t *testing.T) {
 	assert.EqualError(t, err, "telegram: unknown error (400)")
 }
 
+func TestRawContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+	assert.Equal(t, context.Background(), b.Context())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	bc := b.WithContext(ctx)
+	assert.Equal(t, ctx, bc.Context())
+	assert.Equal(t, context.Background(), b.Context())
+
+	_, err = bc.Raw("getMe", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	_, err = bc.Send(&Chat{ID: 1}, &Document{File: FromReader(strings.NewReader("data"))})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	_, err = b.WithContext(ctx).Send(&Chat{ID: 1}, "text")
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
 func TestExtractOk(t *testing.T) {
 	data := []byte(`{"ok": true, "result": {}}`)
 	require.NoError(t, extractOk(data))
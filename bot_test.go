@@ -1,6 +1,7 @@
 package telebot
 
 import (
+	"context"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -107,6 +109,79 @@ func TestBotHandle(t *testing.T) {
 	assert.Contains(t, b.handlers, inline.CallbackUnique())
 }
 
+func TestBotHandleSkip(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var got []string
+	guard := func(text string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c Context) error {
+				if c.Text() != text {
+					return ErrSkip
+				}
+				return next(c)
+			}
+		}
+	}
+
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "default")
+		return nil
+	})
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "a")
+		return nil
+	}, guard("a"))
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "b")
+		return nil
+	}, guard("b"))
+
+	for _, text := range []string{"a", "b", "c"} {
+		b.ProcessUpdate(Update{Message: &Message{Text: text}})
+	}
+	assert.Equal(t, []string{"a", "b", "default"}, got)
+
+	// replaces all the previous handlers
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "new")
+		return nil
+	})
+	b.ProcessUpdate(Update{Message: &Message{Text: "a"}})
+	assert.Equal(t, "new", got[len(got)-1])
+}
+
+func TestBotHandleSkipMiddleware(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var got []string
+	b.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			got = append(got, "bot")
+			return next(c)
+		}
+	})
+
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "default")
+		return nil
+	})
+	b.Handle(OnText, func(c Context) error {
+		return ErrSkip
+	}, func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			got = append(got, "own")
+			return next(c)
+		}
+	})
+
+	// the bot middleware runs once for the whole chain
+	b.ProcessUpdate(Update{Message: &Message{Text: "text"}})
+	assert.Equal(t, []string{"bot", "own", "default"}, got)
+}
+
 func TestBotStart(t *testing.T) {
 	if token == "" {
 		t.Skip("TELEBOT_SECRET is required")
@@ -150,6 +225,46 @@ func TestBotStart(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestBotShutdown(t *testing.T) {
+	b, err := NewBot(Settings{Offline: true})
+	require.NoError(t, err)
+
+	// not started yet
+	require.NoError(t, b.Shutdown(context.Background()))
+
+	tp := newTestPoller()
+	b.Poller = tp
+
+	var (
+		started  = make(chan struct{})
+		release  = make(chan struct{})
+		finished int32
+	)
+	b.Handle(OnText, func(c Context) error {
+		close(started)
+		<-release
+		atomic.StoreInt32(&finished, 1)
+		return nil
+	})
+
+	go b.Start()
+	tp.updates <- Update{Message: &Message{Text: "text"}}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+
+	assert.Equal(t, context.DeadlineExceeded, b.Shutdown(ctx))
+	assert.Zero(t, atomic.LoadInt32(&finished))
+	assert.NoError(t, b.Shutdown(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
+
 func TestBotProcessUpdate(t *testing.T) {
 	b, err := NewBot(Settings{Synchronous: true, Offline: true})
 	if err != nil {
@@ -0,0 +1,56 @@
+package telebot
+
+import "encoding/json"
+
+// Call makes a request to the Bot API method and unmarshals its result
+// into a value of type T. It's a type-safe alternative to Raw, so any
+// method can be used before the wrapper for it lands in telebot.
+//
+// Example:
+//
+//	count, err := tele.Call[int](b, "getChatMemberCount", map[string]string{
+//		"chat_id": chat.Recipient(),
+//	})
+func Call[T any](b *Bot, method string, payload interface{}) (T, error) {
+	data, err := b.Raw(method, payload)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return extractResult[T](data)
+}
+
+// CallFiles is like Call, but uploads the files along with the params.
+// The files already stored on Telegram servers or available by URL are
+// passed as params, the rest are sent using multipart/form-data.
+//
+// Example:
+//
+//	msg, err := tele.CallFiles[*tele.Message](b, "sendDocument",
+//		map[string]string{"chat_id": chat.Recipient()},
+//		map[string]tele.File{"document": tele.FromDisk("report.pdf")},
+//	)
+func CallFiles[T any](b *Bot, method string, params map[string]string, files map[string]File) (T, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	data, err := b.sendFiles(method, files, params)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return extractResult[T](data)
+}
+
+// extractResult extracts the result of type T from given data.
+// Should be called after extractOk or b.Raw() to handle possible errors.
+func extractResult[T any](data []byte) (T, error) {
+	var resp struct {
+		Result T
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp.Result, wrapError(err)
+	}
+	return resp.Result, nil
+}
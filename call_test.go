@@ -0,0 +1,45 @@
+package telebot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getChatMemberCount"):
+			w.Write([]byte(`{"ok":true,"result":42}`))
+		case strings.HasSuffix(r.URL.Path, "/sendDocument"):
+			_, header, err := r.FormFile("document")
+			require.NoError(t, err)
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1,"document":{"file_name":"` + header.Filename + `"}}}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error_code":404,"description":"Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+
+	n, err := Call[int](b, "getChatMemberCount", map[string]string{"chat_id": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, 42, n)
+
+	_, err = Call[bool](b, "unknownMethod", nil)
+	assert.Equal(t, ErrNotFound, err)
+
+	doc := FromReader(strings.NewReader("data"))
+	doc.fileName = "report.txt"
+
+	msg, err := CallFiles[*Message](b, "sendDocument", nil, map[string]File{"document": doc})
+	require.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	assert.Equal(t, "report.txt", msg.Document.FileName)
+}
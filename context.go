@@ -104,6 +104,10 @@ type Context interface {
 	// The message arguments split by space, while the callback's ones by a "|" symbol.
 	Args() []string
 
+	// Matches returns the text matched by the regexp of the handler
+	// set with HandleRegex, followed by the captured groups.
+	Matches() []string
+
 	// Send sends a message to the current recipient.
 	// See Send from bot.go.
 	Send(what interface{}, opts ...interface{}) error
@@ -175,6 +179,12 @@ type Context interface {
 	// RespondAlert sends an alert response for the current callback query.
 	RespondAlert(text string) error
 
+	// Ask sends the question to the current chat and waits for the answer:
+	// the next message of the same user or the press of the question's
+	// inline button. The answer bypasses the handlers. It's limited by
+	// AskTimeout, if passed, along with the send options.
+	Ask(what interface{}, opts ...interface{}) (Context, error)
+
 	// Get retrieves data from the context.
 	Get(key string) interface{}
 
@@ -423,9 +433,14 @@ func (c *nativeContext) Args() []string {
 	return nil
 }
 
+func (c *nativeContext) Matches() []string {
+	matches, _ := c.Get(matchesKey).([]string)
+	return matches
+}
+
 func (c *nativeContext) Send(what interface{}, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
-	_, err := c.b.Send(c.Recipient(), what, opts...)
+	_, err := c.api(opts).Send(c.Recipient(), what, opts...)
 	return err
 }
 
@@ -457,10 +472,25 @@ func (c *nativeContext) inheritOpts(opts ...interface{}) []interface{} {
 	return opts
 }
 
+// api returns the bot the request should be made with,
+// replying to the webhook request if asked to.
+func (c *nativeContext) api(opts []interface{}) API {
+	b, ok := c.b.(*Bot)
+	if !ok {
+		return c.b
+	}
+	for _, opt := range opts {
+		if opt == WebhookReply {
+			return b.webhookReplier(c.u.ID)
+		}
+	}
+	return c.b
+}
+
 func (c *nativeContext) SendAlbum(a Album, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
 
-	_, err := c.b.SendAlbum(c.Recipient(), a, opts...)
+	_, err := c.api(opts).SendAlbum(c.Recipient(), a, opts...)
 	return err
 }
 
@@ -470,7 +500,7 @@ func (c *nativeContext) Reply(what interface{}, opts ...interface{}) error {
 		return ErrBadContext
 	}
 	opts = c.inheritOpts(opts...)
-	_, err := c.b.Reply(msg, what, opts...)
+	_, err := c.api(opts).Reply(msg, what, opts...)
 	return err
 }
 
@@ -492,11 +522,11 @@ func (c *nativeContext) Edit(what interface{}, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
 
 	if c.u.InlineResult != nil {
-		_, err := c.b.Edit(c.u.InlineResult, what, opts...)
+		_, err := c.api(opts).Edit(c.u.InlineResult, what, opts...)
 		return err
 	}
 	if c.u.Callback != nil {
-		_, err := c.b.Edit(c.u.Callback, what, opts...)
+		_, err := c.api(opts).Edit(c.u.Callback, what, opts...)
 		return err
 	}
 	return ErrBadContext
@@ -506,11 +536,11 @@ func (c *nativeContext) EditCaption(caption string, opts ...interface{}) error {
 	opts = c.inheritOpts(opts...)
 
 	if c.u.InlineResult != nil {
-		_, err := c.b.EditCaption(c.u.InlineResult, caption, opts...)
+		_, err := c.api(opts).EditCaption(c.u.InlineResult, caption, opts...)
 		return err
 	}
 	if c.u.Callback != nil {
-		_, err := c.b.EditCaption(c.u.Callback, caption, opts...)
+		_, err := c.api(opts).EditCaption(c.u.Callback, caption, opts...)
 		return err
 	}
 	return ErrBadContext
@@ -583,6 +613,14 @@ func (c *nativeContext) RespondAlert(text string) error {
 	return c.Respond(&CallbackResponse{Text: text, ShowAlert: true})
 }
 
+func (c *nativeContext) Ask(what interface{}, opts ...interface{}) (Context, error) {
+	b, ok := c.b.(*Bot)
+	if !ok {
+		return nil, errors.New("telebot: ask is not supported by the context bot")
+	}
+	return b.ask(c, what, c.inheritOpts(opts...)...)
+}
+
 func (c *nativeContext) Answer(resp *QueryResponse) error {
 	if c.u.Query == nil {
 		return errors.New("telebot: context inline query is nil")
@@ -0,0 +1,154 @@
+package telebot
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// DownloadOptions configures the download of a file with DownloadTo.
+type DownloadOptions struct {
+	// MaxSize limits the size of the file. The download fails with
+	// ErrFileTooBig if either the size reported by Telegram or the
+	// actual one exceeds it. Default: Bot.DownloadLimit().
+	MaxSize int64
+
+	// Offset is the number of bytes of the file to skip, e.g. the
+	// ones already downloaded, so the interrupted download can be
+	// resumed. The rest of the file is requested with HTTP Range.
+	Offset int64
+
+	// Hash is used to compute the hash of the written content.
+	// To get the hash of the whole file on resume, pass the hash
+	// the already downloaded part has been written to.
+	// Default: sha256.New().
+	Hash hash.Hash
+}
+
+// DownloadTo streams the file from Telegram servers to w and
+// returns the hash of the content. Unlike Download, it checks
+// the size of the file, both the reported and the actual one,
+// so the untrusted files can be passed to w safely.
+//
+// Example:
+//
+//		// resuming the interrupted download
+//		f, _ := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0600)
+//		fi, _ := f.Stat()
+//		sum, err := b.DownloadTo(f, &doc.File, tele.DownloadOptions{
+//			MaxSize: 10 << 20,
+//			Offset:  fi.Size(),
+//		})
+//
+func (b *Bot) DownloadTo(w io.Writer, file *File, opts DownloadOptions) ([]byte, error) {
+	max := opts.MaxSize
+	if max <= 0 {
+		max = b.DownloadLimit()
+	}
+	if file.FileSize > max || opts.Offset > max {
+		return nil, ErrFileTooBig
+	}
+
+	f, err := b.FileByID(file.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if f.FileSize > max {
+		return nil, ErrFileTooBig
+	}
+	file.FilePath = f.FilePath // saving file path
+
+	body, size, err := b.openFile(f, opts.Offset)
+	if err != nil {
+		return nil, err
+	}
+	body = file.trackDownload(body, opts.Offset, size)
+	defer body.Close()
+
+	h := opts.Hash
+	if h == nil {
+		h = sha256.New()
+	}
+
+	n, err := io.Copy(io.MultiWriter(w, h), io.LimitReader(body, max-opts.Offset))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	// the file is too big if there's anything left after the limit
+	n += opts.Offset
+	if n == max {
+		var extra [1]byte
+		if _, err := io.ReadFull(body, extra[:]); err == nil {
+			return nil, ErrFileTooBig
+		}
+	}
+	if f.FileSize > 0 && n != f.FileSize {
+		return nil, fmt.Errorf("telebot: downloaded %d bytes of %d: %w", n, f.FileSize, io.ErrUnexpectedEOF)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// openFile opens the file got with getFile, skipping the offset
+// bytes. It returns the file body and its full size, if known.
+func (b *Bot) openFile(f File, offset int64) (io.ReadCloser, int64, error) {
+	size := f.FileSize
+
+	// Local server gives the absolute path to the file on its disk.
+	if b.local && filepath.IsAbs(f.FilePath) {
+		fd, err := os.Open(f.FilePath)
+		if err != nil {
+			return nil, 0, wrapError(err)
+		}
+		if _, err := fd.Seek(offset, io.SeekStart); err != nil {
+			fd.Close()
+			return nil, 0, wrapError(err)
+		}
+		if fi, err := fd.Stat(); err == nil {
+			size = fi.Size()
+		}
+		return fd, size, nil
+	}
+
+	url := b.URL + "/file/bot" + b.Token + "/" + f.FilePath
+
+	req, err := http.NewRequestWithContext(b.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, wrapError(err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, 0, wrapError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if size == 0 && resp.ContentLength >= 0 {
+			size = offset + resp.ContentLength
+		}
+	case resp.StatusCode == http.StatusOK:
+		if size == 0 {
+			size = resp.ContentLength
+		}
+		// the range is ignored, so skipping it manually
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, 0, wrapError(err)
+		}
+	default:
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("telebot: expected status 200 but got %s", resp.Status)
+	}
+
+	return resp.Body, size, nil
+}
@@ -0,0 +1,71 @@
+package telebot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadTo(t *testing.T) {
+	const content = "0123456789"
+
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getFile"):
+			var params map[string]string
+			json.NewDecoder(r.Body).Decode(&params)
+			if params["file_id"] == "unsized" {
+				w.Write([]byte(`{"ok":true,"result":{"file_path":"file"}}`))
+			} else {
+				w.Write([]byte(`{"ok":true,"result":{"file_path":"file","file_size":10}}`))
+			}
+		default:
+			ranges = append(ranges, r.Header.Get("Range"))
+			http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	sum, err := b.DownloadTo(&buf, &File{FileID: "id"}, DownloadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, content, buf.String())
+
+	expected := sha256.Sum256([]byte(content))
+	assert.Equal(t, expected[:], sum)
+
+	// resuming with the hash of the downloaded part
+	h := sha256.New()
+	h.Write([]byte(content[:4]))
+	buf.Reset()
+
+	sum, err = b.DownloadTo(&buf, &File{FileID: "id"}, DownloadOptions{Offset: 4, Hash: h})
+	require.NoError(t, err)
+	assert.Equal(t, content[4:], buf.String())
+	assert.Equal(t, expected[:], sum)
+	assert.Equal(t, []string{"", "bytes=4-"}, ranges)
+
+	_, err = b.DownloadTo(io.Discard, &File{FileID: "id"}, DownloadOptions{MaxSize: 5})
+	assert.Equal(t, ErrFileTooBig, err)
+	_, err = b.DownloadTo(io.Discard, &File{FileID: "id", FileSize: 100}, DownloadOptions{MaxSize: 50})
+	assert.Equal(t, ErrFileTooBig, err)
+
+	// the actual size is checked as well
+	buf.Reset()
+	_, err = b.DownloadTo(&buf, &File{FileID: "unsized"}, DownloadOptions{MaxSize: 5})
+	assert.Equal(t, ErrFileTooBig, err)
+	assert.Equal(t, 5, buf.Len())
+}
@@ -91,6 +91,7 @@ var (
 	ErrEmptyMessage           = NewError(400, "Bad Request: message must be non-empty")
 	ErrEmptyText              = NewError(400, "Bad Request: text is empty")
 	ErrFailedImageProcess     = NewError(400, "Bad Request: IMAGE_PROCESS_FAILED", "Image process failed")
+	ErrFileTooBig             = NewError(400, "Bad Request: file is too big")
 	ErrGroupMigrated          = NewError(400, "Bad Request: group chat was upgraded to a supergroup chat")
 	ErrMessageNotModified     = NewError(400, "Bad Request: message is not modified")
 	ErrNoRightsToDelete       = NewError(400, "Bad Request: message can't be deleted")
@@ -136,6 +137,12 @@ var (
 	ErrNotChannelMember     = NewError(403, "Forbidden: bot is not a member of the channel chat")
 )
 
+// Conflict errors
+var (
+	ErrTerminatedByOtherGetUpdates = NewError(409, "Conflict: terminated by other getUpdates request; make sure that only one bot instance is running")
+	ErrWebhookIsActive             = NewError(409, "Conflict: can't use getUpdates method while webhook is active; use deleteWebhook to delete the webhook first")
+)
+
 // Err returns Error instance by given description.
 func Err(s string) error {
 	switch s {
@@ -175,6 +182,8 @@ func Err(s string) error {
 		return ErrEmptyText
 	case ErrFailedImageProcess.ʔ():
 		return ErrFailedImageProcess
+	case ErrFileTooBig.ʔ():
+		return ErrFileTooBig
 	case ErrGroupMigrated.ʔ():
 		return ErrGroupMigrated
 	case ErrMessageNotModified.ʔ():
@@ -253,6 +262,10 @@ func Err(s string) error {
 		return ErrChannelsTooMuchUser
 	case ErrNotChannelMember.ʔ():
 		return ErrNotChannelMember
+	case ErrTerminatedByOtherGetUpdates.ʔ():
+		return ErrTerminatedByOtherGetUpdates
+	case ErrWebhookIsActive.ʔ():
+		return ErrWebhookIsActive
 	default:
 		return nil
 	}
@@ -21,8 +21,17 @@ type File struct {
 	FileURL string `json:"file_url"`
 
 	// FileReader is used for file backed with io.Reader.
+	// If it's an io.Seeker, it's rewound when the upload is retried.
 	FileReader io.Reader `json:"-"`
 
+	// FileOpener is used for file backed with io.Reader which
+	// is opened anew on every upload attempt. The reader is
+	// closed after the upload if it's an io.Closer.
+	FileOpener func() (io.Reader, error) `json:"-"`
+
+	// OnProgress reports the progress of the file upload or download.
+	OnProgress ProgressFunc `json:"-"`
+
 	fileName string
 }
 
@@ -65,6 +74,17 @@ func FromReader(reader io.Reader) File {
 	return File{FileReader: reader}
 }
 
+// FromOpener constructs a new file from the function opening io.Reader.
+// Unlike FromReader, the file can be read again to retry the upload.
+//
+//		photo := &tele.Photo{File: tele.FromOpener(func() (io.Reader, error) {
+//			return os.Open("chicken.jpg")
+//		})}
+//
+func FromOpener(open func() (io.Reader, error)) File {
+	return File{FileOpener: open}
+}
+
 func (f *File) stealRef(g *File) {
 	if g.OnDisk() {
 		f.FileLocal = g.FileLocal
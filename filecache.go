@@ -0,0 +1,193 @@
+package telebot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// FileCache stores the IDs of the uploaded files, so the same files
+// are sent by their IDs next time instead of being uploaded again.
+//
+// The files on disk are keyed by their path and modification time,
+// and the ones backed with readers by the hash of their content.
+// Notice the reader which is not an io.Seeker is read into memory
+// to be hashed before the upload.
+type FileCache interface {
+	// Get returns the file ID stored by the key, or empty
+	// string if there is none.
+	Get(key string) (string, error)
+
+	// Set stores the file ID by the key.
+	Set(key, id string) error
+
+	// Delete removes the file ID stored by the key.
+	Delete(key string) error
+}
+
+// MemoryFileCache is an in-memory FileCache.
+type MemoryFileCache struct {
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// Get implements FileCache.
+func (c *MemoryFileCache) Get(key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ids[key], nil
+}
+
+// Set implements FileCache.
+func (c *MemoryFileCache) Set(key, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ids == nil {
+		c.ids = make(map[string]string)
+	}
+	c.ids[key] = id
+	return nil
+}
+
+// Delete implements FileCache.
+func (c *MemoryFileCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.ids, key)
+	return nil
+}
+
+// cachedFile returns the copy of the file with the cached ID, if any,
+// and its cache key, which is empty if the file is not to be cached.
+// The cache failures are reported with OnError, so the file is just
+// uploaded then.
+func (b *Bot) cachedFile(kind string, f *File) (File, string) {
+	cp := *f
+	if b.fileCache == nil || cp.InCloud() || cp.FileURL != "" {
+		return cp, ""
+	}
+
+	key, err := fileCacheKey(kind, &cp)
+	f.FileReader = cp.FileReader // might be read into memory
+
+	if err == nil && key != "" && !b.reupload {
+		cp.FileID, err = b.fileCache.Get(key)
+	}
+	if err != nil {
+		b.OnError(wrapError(err), nil)
+		return cp, ""
+	}
+	return cp, key
+}
+
+// rememberFile stores the ID of the uploaded file by its key.
+func (b *Bot) rememberFile(key string, m Media) {
+	if key == "" || m == nil || m.MediaFile() == nil || m.MediaFile().FileID == "" {
+		return
+	}
+	if err := b.fileCache.Set(key, m.MediaFile().FileID); err != nil {
+		b.OnError(wrapError(err), nil)
+	}
+}
+
+// forgetFile removes the file ID rejected by Telegram from the cache.
+func (b *Bot) forgetFile(key string) {
+	if err := b.fileCache.Delete(key); err != nil {
+		b.OnError(wrapError(err), nil)
+	}
+}
+
+// rewindFiles returns a function rewinding the readers of the files,
+// so they can be uploaded again once the cached file ID is rejected.
+func rewindFiles(files map[string]File) func() error {
+	rewind, ok := rewinder(files)
+	if !ok {
+		return func() error { return nil }
+	}
+	return rewind
+}
+
+// reuploading returns the bot uploading the files instead of sending
+// the cached IDs, which are updated once the files are uploaded.
+func (b *Bot) reuploading() *Bot {
+	b2 := *b
+	b2.reupload = true
+	return &b2
+}
+
+// fileCacheKey returns the cache key of the file of the given media
+// type. The reader of the file may be replaced, if it's read to get
+// the hash of its content.
+func fileCacheKey(kind string, f *File) (string, error) {
+	switch {
+	case f.OnDisk():
+		path, err := filepath.Abs(f.FileLocal)
+		if err != nil {
+			return "", err
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		return kind + ":file:" + path + ":" + strconv.FormatInt(fi.ModTime().UnixNano(), 10), nil
+	case f.FileOpener != nil:
+		r, err := f.FileOpener()
+		if err != nil {
+			return "", err
+		}
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		return hashKey(kind, r)
+	case f.FileReader != nil:
+		s, ok := f.FileReader.(io.Seeker)
+		if !ok {
+			data, err := ioutil.ReadAll(f.FileReader)
+			if err != nil {
+				return "", err
+			}
+			f.FileReader = bytes.NewReader(data)
+			return hashKey(kind, bytes.NewReader(data))
+		}
+
+		off, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", err
+		}
+		key, err := hashKey(kind, f.FileReader)
+		if err != nil {
+			return "", err
+		}
+		if _, err := s.Seek(off, io.SeekStart); err != nil {
+			return "", err
+		}
+		return key, nil
+	default:
+		return "", nil
+	}
+}
+
+func hashKey(kind string, r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return kind + ":sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isWrongFileID tells whether Telegram rejected the file ID.
+func isWrongFileID(err error) bool {
+	return errors.Is(err, ErrWrongFileID) ||
+		errors.Is(err, ErrWrongFileIDCharacter) ||
+		errors.Is(err, ErrWrongFileIDLength) ||
+		errors.Is(err, ErrWrongFileIDPadding) ||
+		errors.Is(err, ErrWrongFileIDSymbol)
+}
@@ -0,0 +1,70 @@
+package telebot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banner.jpg")
+	require.NoError(t, os.WriteFile(path, []byte("banner"), 0600))
+
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc string
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			r.ParseMultipartForm(1 << 20)
+			f, _, _ := r.FormFile("document")
+			data, _ := io.ReadAll(f)
+			doc = "upload:" + string(data)
+		} else {
+			var params map[string]string
+			json.NewDecoder(r.Body).Decode(&params)
+			doc = params["document"]
+		}
+		sent = append(sent, doc)
+
+		if doc == "stale" {
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"` + ErrWrongFileID.Description + `"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"document":{"file_id":"id-` + strings.TrimPrefix(doc, "upload:") + `"}}}`))
+	}))
+	defer srv.Close()
+
+	cache := &MemoryFileCache{}
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true, FileCache: cache})
+	require.NoError(t, err)
+
+	send := func(f File) {
+		_, err := b.Send(&Chat{ID: 1}, &Document{File: f, FileName: "file"})
+		require.NoError(t, err)
+	}
+
+	send(FromDisk(path))
+	send(FromDisk(path))
+	send(FromReader(strings.NewReader("reader")))
+	send(FromReader(io.LimitReader(strings.NewReader("reader"), 6)))
+	assert.Equal(t, []string{"upload:banner", "id-banner", "upload:reader", "id-reader"}, sent)
+
+	// the file is uploaded again once its ID is rejected
+	sent = nil
+	key, err := fileCacheKey("document", &File{FileLocal: path})
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(key, "stale"))
+
+	send(FromDisk(path))
+	assert.Equal(t, []string{"stale", "upload:banner"}, sent)
+
+	id, _ := cache.Get(key)
+	assert.Equal(t, "id-banner", id)
+}
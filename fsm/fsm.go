@@ -0,0 +1,288 @@
+// Package fsm implements the finite state machine of the multi-step
+// dialogs, such as collecting the name, then the phone, and then the
+// confirmation. The states are kept per user in the chat, so the
+// handlers of the same endpoint are picked by the current state.
+//
+// Example:
+//
+//		m := fsm.New(fsm.NewFileStorage("states.json"))
+//		m.TTL = time.Hour
+//		m.OnEnter("await_phone", func(c tele.Context) error {
+//			return c.Send("What's your phone?")
+//		})
+//		m.Attach(b)
+//
+//		b.Handle("/signup", func(c tele.Context) error {
+//			return fsm.Enter(c, "await_phone")
+//		})
+//		b.Handle(tele.OnText, onPhone, fsm.In("await_phone"))
+//
+package fsm
+
+import (
+	"errors"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+const (
+	machineKey = "fsm.machine"
+	entryKey   = "fsm.entry"
+)
+
+// ErrNoMachine is returned when the context is not handled
+// with the Machine's middleware.
+var ErrNoMachine = errors.New("fsm: machine middleware is not used")
+
+// Machine keeps the states of the dialogs and runs their hooks.
+type Machine struct {
+	// Storage persists the states. Default: NewMemoryStorage().
+	Storage Storage
+
+	// TTL is the time the dialog may stay in a state, so the
+	// abandoned dialogs are expired. Default: 0, no expiry.
+	TTL time.Duration
+
+	// CancelCommand finishes the dialog in any state, instead of
+	// being handled as usual. Default: "/cancel".
+	CancelCommand string
+
+	// OnCancel is called once the dialog is cancelled,
+	// e.g. to confirm it to the user.
+	OnCancel tele.HandlerFunc
+
+	enter map[string]tele.HandlerFunc
+	exit  map[string]tele.HandlerFunc
+}
+
+// New returns the machine keeping the states in the storage.
+func New(storage Storage) *Machine {
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+	return &Machine{Storage: storage}
+}
+
+// OnEnter sets the hook called once the dialog enters the state.
+func (m *Machine) OnEnter(state string, h tele.HandlerFunc) {
+	if m.enter == nil {
+		m.enter = make(map[string]tele.HandlerFunc)
+	}
+	m.enter[state] = h
+}
+
+// OnExit sets the hook called once the dialog leaves the state,
+// either to the next state, finished or cancelled.
+func (m *Machine) OnExit(state string, h tele.HandlerFunc) {
+	if m.exit == nil {
+		m.exit = make(map[string]tele.HandlerFunc)
+	}
+	m.exit[state] = h
+}
+
+// Attach sets the machine up with the bot: adds its middleware
+// and the handler of the cancel command. It should be called before
+// the handlers are set, so the middleware is applied to them.
+func (m *Machine) Attach(b *tele.Bot) {
+	b.Use(m.Middleware())
+	b.Handle(m.cancelCommand(), func(c tele.Context) error {
+		return nil
+	})
+}
+
+// Middleware returns the middleware making the machine available
+// to the handlers and cancelling the dialogs with CancelCommand.
+func (m *Machine) Middleware() tele.MiddlewareFunc {
+	if m.Storage == nil {
+		m.Storage = NewMemoryStorage()
+	}
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			c.Set(machineKey, m)
+
+			if msg := c.Message(); msg != nil && c.Callback() == nil && m.isCancel(msg.Text) {
+				state, err := m.Current(c)
+				if err != nil {
+					return err
+				}
+				if state != "" {
+					return m.Cancel(c)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Current returns the current state of the dialog,
+// or empty string if there is none.
+func (m *Machine) Current(c tele.Context) (string, error) {
+	if e, ok := c.Get(entryKey).(Entry); ok && !e.Expired(time.Now()) {
+		return e.State, nil
+	}
+
+	e, err := m.Storage.Get(key(c))
+	if err != nil {
+		return "", err
+	}
+	if e.Expired(time.Now()) {
+		e = Entry{}
+	}
+
+	c.Set(entryKey, e)
+	return e.State, nil
+}
+
+// Enter moves the dialog to the state, calling the exit hook
+// of the current state and the entry hook of the new one.
+func (m *Machine) Enter(c tele.Context, state string) error {
+	if err := m.leave(c); err != nil {
+		return err
+	}
+
+	e := Entry{State: state}
+	if m.TTL > 0 {
+		e.Expires = time.Now().Add(m.TTL)
+	}
+	if err := m.Storage.Set(key(c), e); err != nil {
+		return err
+	}
+	c.Set(entryKey, e)
+
+	if h := m.enter[state]; h != nil {
+		return h(c)
+	}
+	return nil
+}
+
+// Finish finishes the dialog, calling the exit hook
+// of the current state.
+func (m *Machine) Finish(c tele.Context) error {
+	if err := m.leave(c); err != nil {
+		return err
+	}
+	if err := m.Storage.Delete(key(c)); err != nil {
+		return err
+	}
+	c.Set(entryKey, Entry{})
+	return nil
+}
+
+// Cancel finishes the dialog and calls OnCancel.
+func (m *Machine) Cancel(c tele.Context) error {
+	if err := m.Finish(c); err != nil {
+		return err
+	}
+	if m.OnCancel != nil {
+		return m.OnCancel(c)
+	}
+	return nil
+}
+
+// In returns the middleware running the handler only if the dialog
+// is in one of the states, otherwise the update is passed to the
+// handler set for the endpoint before. The empty state stands for
+// no dialog.
+func (m *Machine) In(states ...string) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			state, err := m.Current(c)
+			if err != nil {
+				return err
+			}
+			for _, s := range states {
+				if s == state {
+					return next(c)
+				}
+			}
+			return tele.ErrSkip
+		}
+	}
+}
+
+// leave calls the exit hook of the current state, if any.
+func (m *Machine) leave(c tele.Context) error {
+	state, err := m.Current(c)
+	if err != nil || state == "" {
+		return err
+	}
+	if h := m.exit[state]; h != nil {
+		return h(c)
+	}
+	return nil
+}
+
+func (m *Machine) cancelCommand() string {
+	if m.CancelCommand == "" {
+		return "/cancel"
+	}
+	return m.CancelCommand
+}
+
+// isCancel tells whether the text is the cancel command,
+// possibly addressed to the bot by its username.
+func (m *Machine) isCancel(text string) bool {
+	cmd := m.cancelCommand()
+	if len(text) < len(cmd) || text[:len(cmd)] != cmd {
+		return false
+	}
+	rest := text[len(cmd):]
+	return rest == "" || rest[0] == '@' || rest[0] == ' '
+}
+
+// In returns the middleware running the handler only if the dialog
+// is in one of the states, see Machine.In. The machine is taken from
+// the context, so its middleware must be used.
+func In(states ...string) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			m, ok := c.Get(machineKey).(*Machine)
+			if !ok {
+				return ErrNoMachine
+			}
+			return m.In(states...)(next)(c)
+		}
+	}
+}
+
+// Current returns the current state of the dialog,
+// see Machine.Current.
+func Current(c tele.Context) (string, error) {
+	m, ok := c.Get(machineKey).(*Machine)
+	if !ok {
+		return "", ErrNoMachine
+	}
+	return m.Current(c)
+}
+
+// Enter moves the dialog to the state, see Machine.Enter.
+func Enter(c tele.Context, state string) error {
+	m, ok := c.Get(machineKey).(*Machine)
+	if !ok {
+		return ErrNoMachine
+	}
+	return m.Enter(c, state)
+}
+
+// Finish finishes the dialog, see Machine.Finish.
+func Finish(c tele.Context) error {
+	m, ok := c.Get(machineKey).(*Machine)
+	if !ok {
+		return ErrNoMachine
+	}
+	return m.Finish(c)
+}
+
+// key returns the key of the dialog in the context.
+func key(c tele.Context) Key {
+	var k Key
+	if chat := c.Chat(); chat != nil {
+		k.ChatID = chat.ID
+	}
+	if user := c.Sender(); user != nil {
+		k.UserID = user.ID
+	}
+	return k
+}
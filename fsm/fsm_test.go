@@ -0,0 +1,152 @@
+package fsm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+	"gopkg.in/telebot.v4/teletest"
+)
+
+func TestMachine(t *testing.T) {
+	b, err := tele.NewBot(tele.Settings{Offline: true, Synchronous: true})
+	require.NoError(t, err)
+
+	var got []string
+	log := func(s string) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			got = append(got, s)
+			return nil
+		}
+	}
+
+	m := New(nil)
+	m.OnEnter("name", log("enter name"))
+	m.OnExit("name", log("exit name"))
+	m.OnEnter("phone", log("enter phone"))
+	m.OnExit("phone", log("exit phone"))
+	m.OnCancel = log("cancelled")
+	m.Attach(b)
+
+	b.Handle("/signup", func(c tele.Context) error {
+		return Enter(c, "name")
+	})
+	b.Handle(tele.OnText, log("text"))
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		got = append(got, "name: "+c.Text())
+		return Enter(c, "phone")
+	}, In("name"))
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		got = append(got, "phone: "+c.Text())
+		return Finish(c)
+	}, In("phone"))
+
+	user := &tele.User{ID: 1}
+	other := &tele.User{ID: 2}
+
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Command("/signup"))
+	b.ProcessUpdate(teletest.NewUpdate().From(other).Text("John"))
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Text("John"))
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Text("+123"))
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Text("done"))
+
+	assert.Equal(t, []string{
+		"enter name",
+		"text",
+		"name: John",
+		"exit name",
+		"enter phone",
+		"phone: +123",
+		"exit phone",
+		"text",
+	}, got)
+
+	got = nil
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Command("/signup"))
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Command("/cancel"))
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Command("/cancel"))
+	b.ProcessUpdate(teletest.NewUpdate().From(user).Text("John"))
+
+	assert.Equal(t, []string{
+		"enter name",
+		"exit name",
+		"cancelled",
+		"text",
+	}, got)
+}
+
+func TestMachineTTL(t *testing.T) {
+	b, err := tele.NewBot(tele.Settings{Offline: true, Synchronous: true})
+	require.NoError(t, err)
+
+	storage := NewMemoryStorage()
+	m := New(storage)
+	m.TTL = time.Minute
+	m.Attach(b)
+
+	var state string
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		state, err = Current(c)
+		return err
+	})
+	b.Handle("/start", func(c tele.Context) error {
+		return Enter(c, "started")
+	})
+
+	b.ProcessUpdate(teletest.NewUpdate().Command("/start"))
+	b.ProcessUpdate(teletest.NewUpdate().Text("hi"))
+	assert.Equal(t, "started", state)
+
+	k := Key{ChatID: teletest.DefaultUser.ID, UserID: teletest.DefaultUser.ID}
+	e, _ := storage.Get(k)
+	require.NoError(t, storage.Set(k, Entry{State: e.State, Expires: time.Now().Add(-time.Second)}))
+
+	b.ProcessUpdate(teletest.NewUpdate().Text("hi"))
+	assert.Equal(t, "", state)
+}
+
+func TestMachineNoMiddleware(t *testing.T) {
+	var got error
+	b, err := tele.NewBot(tele.Settings{
+		Offline:     true,
+		Synchronous: true,
+		OnError:     func(err error, c tele.Context) { got = err },
+	})
+	require.NoError(t, err)
+
+	b.Handle(tele.OnText, func(c tele.Context) error { return nil }, In("state"))
+
+	b.ProcessUpdate(teletest.NewUpdate().Text("hi"))
+	assert.Equal(t, ErrNoMachine, got)
+}
+
+func TestFileStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "states.json")
+	k1, k2 := Key{ChatID: 1, UserID: 1}, Key{ChatID: -1, UserID: 2}
+
+	s := NewFileStorage(path)
+	e, err := s.Get(k1)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{}, e)
+
+	require.NoError(t, s.Set(k1, Entry{State: "a"}))
+	require.NoError(t, s.Set(k2, Entry{State: "b", Expires: time.Now().Add(-time.Second)}))
+
+	s = NewFileStorage(path)
+	e, err = s.Get(k1)
+	require.NoError(t, err)
+	assert.Equal(t, "a", e.State)
+
+	e, err = s.Get(k2)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{}, e)
+
+	require.NoError(t, s.Delete(k1))
+	e, err = s.Get(k1)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{}, e)
+}
@@ -0,0 +1,190 @@
+package fsm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v4/internal/atomicfile"
+)
+
+// Key identifies the dialog: the user in the chat.
+type Key struct {
+	ChatID int64
+	UserID int64
+}
+
+// String returns the key in "chat:user" form.
+func (k Key) String() string {
+	return fmt.Sprintf("%d:%d", k.ChatID, k.UserID)
+}
+
+// Entry is the stored state of the dialog.
+type Entry struct {
+	State string `json:"state"`
+
+	// Expires is the time the state expires at,
+	// zero if it never does.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Expired tells whether the state is expired by the time.
+func (e Entry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Storage persists the states of the dialogs.
+type Storage interface {
+	// Get returns the entry stored by the key,
+	// or the empty one if there is none.
+	Get(key Key) (Entry, error)
+
+	// Set stores the entry by the key.
+	Set(key Key, e Entry) error
+
+	// Delete removes the entry stored by the key.
+	Delete(key Key) error
+}
+
+// MemoryStorage is an in-memory Storage.
+type MemoryStorage struct {
+	mu      sync.Mutex
+	entries map[Key]Entry
+}
+
+// NewMemoryStorage returns an empty in-memory storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{entries: make(map[Key]Entry)}
+}
+
+// Get implements Storage.
+// The expired entries are removed once they are got.
+func (s *MemoryStorage) Get(key Key) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entries[key]
+	if e.Expired(time.Now()) {
+		delete(s.entries, key)
+		return Entry{}, nil
+	}
+	return e, nil
+}
+
+// Set implements Storage.
+func (s *MemoryStorage) Set(key Key, e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = e
+	return nil
+}
+
+// Delete implements Storage.
+func (s *MemoryStorage) Delete(key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// FileStorage is a Storage backed by a JSON file, so the dialogs
+// survive the restarts. The whole file is rewritten on every change,
+// which is fine for the moderate number of the ongoing dialogs.
+type FileStorage struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileStorage returns a Storage saving the states to the file.
+func NewFileStorage(path string) *FileStorage {
+	return &FileStorage{Path: path}
+}
+
+// Get implements Storage.
+func (s *FileStorage) Get(key Key) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := entries[key.String()]
+	if e.Expired(time.Now()) {
+		return Entry{}, nil
+	}
+	return e, nil
+}
+
+// Set implements Storage.
+func (s *FileStorage) Set(key Key, e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	entries[key.String()] = e
+	return s.save(entries)
+}
+
+// Delete implements Storage.
+func (s *FileStorage) Delete(key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key.String()]; !ok {
+		return nil
+	}
+	delete(entries, key.String())
+	return s.save(entries)
+}
+
+// load reads the entries from the file,
+// it returns no entries if the file doesn't exist yet.
+func (s *FileStorage) load() (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return entries, nil
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("fsm: %s: %w", s.Path, err)
+	}
+	return entries, nil
+}
+
+// save writes the entries to the file, dropping the expired ones.
+func (s *FileStorage) save(entries map[string]Entry) error {
+	now := time.Now()
+	for k, e := range entries {
+		if e.Expired(now) {
+			delete(entries, k)
+		}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(s.Path, data)
+}
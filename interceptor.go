@@ -0,0 +1,55 @@
+package telebot
+
+import "context"
+
+// Request is an outgoing Bot API request passed through the interceptors.
+type Request struct {
+	// Context is the request context, see Bot.WithContext.
+	Context context.Context
+
+	// Method is the Bot API method name.
+	Method string
+
+	// Params is the request payload. It is always
+	// a map[string]string for the requests with files.
+	Params interface{}
+
+	// Files are the files to upload, if any.
+	Files map[string]File
+}
+
+// RequestFunc makes the Bot API request and returns the raw response.
+type RequestFunc func(*Request) ([]byte, error)
+
+// Interceptor wraps every Bot API request made by the bot, so it can
+// inspect or change the request, measure it, or handle its response
+// and error. It's the same concept as MiddlewareFunc for the handlers.
+//
+// Example:
+//
+//	b.Intercept(func(next tele.RequestFunc) tele.RequestFunc {
+//		return func(r *tele.Request) ([]byte, error) {
+//			start := time.Now()
+//			data, err := next(r)
+//			log.Println(r.Method, time.Since(start), err)
+//			return data, err
+//		}
+//	})
+type Interceptor func(RequestFunc) RequestFunc
+
+// Intercept adds interceptors to the chain of the bot requests.
+// The first one is the outermost. Notice the bot copies made
+// by WithContext before the call are not affected.
+func (b *Bot) Intercept(interceptors ...Interceptor) {
+	b.interceptors = append(b.interceptors, interceptors...)
+}
+
+func (b *Bot) intercepted(req *Request) ([]byte, error) {
+	req.Context = b.Context()
+
+	f := b.request
+	for i := len(b.interceptors) - 1; i >= 0; i-- {
+		f = b.interceptors[i](f)
+	}
+	return f(req)
+}
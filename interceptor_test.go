@@ -0,0 +1,61 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		text := r.FormValue("text")
+		if text == "" {
+			var params map[string]string
+			json.NewDecoder(r.Body).Decode(&params)
+			text = params["text"]
+		}
+		w.Write([]byte(`{"ok":true,"result":{"text":"` + text + `"}}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Interceptors: []Interceptor{
+			func(next RequestFunc) RequestFunc {
+				return func(r *Request) ([]byte, error) {
+					calls = append(calls, r.Method)
+					return next(r)
+				}
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	b.Intercept(func(next RequestFunc) RequestFunc {
+		return func(r *Request) ([]byte, error) {
+			if params, ok := r.Params.(map[string]string); ok {
+				params["text"] = strings.ToUpper(params["text"])
+			}
+			if r.Files != nil {
+				calls = append(calls, "files")
+			}
+			return next(r)
+		}
+	})
+
+	msg, err := b.Send(&Chat{ID: 1}, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", msg.Text)
+
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: FromReader(strings.NewReader("data"))})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sendMessage", "sendDocument", "files"}, calls)
+}
@@ -0,0 +1,216 @@
+package telebot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter is an outbound scheduler, which keeps the bot within
+// the Telegram broadcasting limits. Every message sent to a chat
+// waits for its turn, so a broadcast or a busy group never trips
+// the flood control. If it does, the request is retried after
+// the time given by FloodError.RetryAfter.
+//
+// Limits are applied by spacing the messages evenly, i.e. with
+// the default settings, two messages to the same group are sent
+// at least three seconds apart.
+//
+// Example:
+//
+//	limiter := &tele.Limiter{}
+//
+//	b, err := tele.NewBot(tele.Settings{
+//		Token:   "...",
+//		Limiter: limiter,
+//	})
+//
+//	// ...
+//	log.Println("queued messages:", limiter.Pending())
+type Limiter struct {
+	// Global is the number of messages per second allowed
+	// across all chats. Default: 30.
+	Global int
+
+	// Private is the number of messages per second allowed
+	// for a single private chat. Default: 1.
+	Private int
+
+	// Group is the number of messages per minute allowed
+	// for a single group or channel. Default: 20.
+	Group int
+
+	// Retries is the number of attempts to resend a message
+	// upon FloodError. Negative value disables retries. Default: 3.
+	// Ignored if the bot has a RetryPolicy, which retries them instead.
+	Retries int
+
+	mu      sync.Mutex
+	next    time.Time
+	chats   map[string]time.Time
+	swept   time.Time
+	pending int
+}
+
+// Pending returns the number of requests waiting for their turn.
+func (l *Limiter) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pending
+}
+
+func (l *Limiter) retries() int {
+	switch {
+	case l.Retries < 0:
+		return 0
+	case l.Retries == 0:
+		return 3
+	default:
+		return l.Retries
+	}
+}
+
+func (l *Limiter) interval(chat string) (global, local time.Duration) {
+	global = time.Second / time.Duration(orDefault(l.Global, 30))
+	if id, err := strconv.ParseInt(chat, 10, 64); err == nil && id > 0 {
+		local = time.Second / time.Duration(orDefault(l.Private, 1))
+	} else {
+		local = time.Minute / time.Duration(orDefault(l.Group, 20))
+	}
+	return global, local
+}
+
+func orDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// reserve books the earliest allowed time slot for the chat.
+func (l *Limiter) reserve(chat string) time.Time {
+	global, local := l.interval(chat)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.chats == nil {
+		l.chats = make(map[string]time.Time)
+	}
+	if now.Sub(l.swept) > time.Minute {
+		for k, t := range l.chats {
+			if t.Before(now) {
+				delete(l.chats, k)
+			}
+		}
+		l.swept = now
+	}
+
+	at := now
+	if l.next.After(at) {
+		at = l.next
+	}
+	if t := l.chats[chat]; t.After(at) {
+		at = t
+	}
+
+	l.next = at.Add(global)
+	l.chats[chat] = at.Add(local)
+	l.pending++
+	return at
+}
+
+// delay postpones all the upcoming messages to the chat.
+func (l *Limiter) delay(chat string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.chats == nil {
+		l.chats = make(map[string]time.Time)
+	}
+	if t := time.Now().Add(d); t.After(l.chats[chat]) {
+		l.chats[chat] = t
+	}
+}
+
+func (l *Limiter) wait(ctx context.Context, chat string) error {
+	at := l.reserve(chat)
+	defer func() {
+		l.mu.Lock()
+		l.pending--
+		l.mu.Unlock()
+	}()
+
+	d := time.Until(at)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return wrapError(ctx.Err())
+	}
+}
+
+// do calls f once the chat is allowed to receive a message.
+// If retry is set, f is called again upon FloodError.
+func (l *Limiter) do(ctx context.Context, chat string, retry bool, f func() ([]byte, error)) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(ctx, chat); err != nil {
+			return nil, err
+		}
+
+		data, err := f()
+
+		var flood FloodError
+		if !errors.As(err, &flood) {
+			return data, err
+		}
+
+		l.delay(chat, time.Duration(flood.RetryAfter)*time.Second)
+		if !retry || attempt >= l.retries() {
+			return data, err
+		}
+	}
+}
+
+// limited passes the request through the limiter, if there is any
+// and the method sends a message to the chat.
+func (b *Bot) limited(method string, params interface{}, retry bool, f func() ([]byte, error)) ([]byte, error) {
+	if b.limiter == nil || !isSendMethod(method) {
+		return f()
+	}
+
+	var chat string
+	if p, ok := params.(map[string]string); ok {
+		chat = p["chat_id"]
+	}
+	if chat == "" {
+		return f()
+	}
+
+	// the retry policy, if any, retries the flood errors itself
+	return b.limiter.do(b.Context(), chat, retry && b.retry == nil, f)
+}
+
+func isSendMethod(method string) bool {
+	switch {
+	case method == "sendChatAction":
+		return false
+	case strings.HasPrefix(method, "send"),
+		strings.HasPrefix(method, "forwardMessage"),
+		strings.HasPrefix(method, "copyMessage"):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+package telebot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	l := &Limiter{Private: 10}
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true, Limiter: l})
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := b.Send(&Chat{ID: 1}, "text")
+		require.NoError(t, err)
+	}
+
+	// the flood error is retried, and every message
+	// to a private chat is spaced by 100ms
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(300*time.Millisecond))
+	assert.Zero(t, l.Pending())
+
+	// chat actions are not limited
+	start = time.Now()
+	require.NoError(t, b.Notify(&Chat{ID: 1}, Typing))
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+
+	l.Retries = -1
+	atomic.StoreInt32(&calls, 0)
+	_, err = b.Send(&Chat{ID: 2}, "text")
+	assert.IsType(t, FloodError{}, err)
+}
+
+func TestLimiterInterval(t *testing.T) {
+	l := &Limiter{}
+
+	global, local := l.interval("1")
+	assert.Equal(t, time.Second/30, global)
+	assert.Equal(t, time.Second, local)
+
+	_, local = l.interval("-100123")
+	assert.Equal(t, 3*time.Second, local)
+
+	_, local = l.interval("@channel")
+	assert.Equal(t, 3*time.Second, local)
+}
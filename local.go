@@ -0,0 +1,96 @@
+package telebot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File size limits of the Bot API servers, in bytes.
+const (
+	CloudUploadLimit   = 50 << 20
+	CloudDownloadLimit = 20 << 20
+	LocalFileLimit     = 2000 << 20
+)
+
+// UploadLimit returns the maximum size of a file the bot can upload.
+// The larger files on disk are rejected with ErrFileTooBig.
+func (b *Bot) UploadLimit() int64 {
+	if b.local {
+		return LocalFileLimit
+	}
+	return CloudUploadLimit
+}
+
+// tooBig tells whether the file to upload, the one on disk
+// or the one with the known size, exceeds UploadLimit.
+func (b *Bot) tooBig(f *File) bool {
+	size := f.FileSize
+	if fi, err := os.Stat(f.FileLocal); err == nil {
+		size = fi.Size()
+	}
+	return size > b.UploadLimit()
+}
+
+// DownloadLimit returns the maximum size of a file the bot can download.
+func (b *Bot) DownloadLimit() int64 {
+	if b.local {
+		return LocalFileLimit
+	}
+	return CloudDownloadLimit
+}
+
+// MoveTo moves the bot to the Bot API server at the given URL and
+// sets its local mode. Before that, the bot is logged out from the
+// cloud server, or closed on the current local one, as Telegram
+// requires. The bot must be stopped while moving.
+//
+// Notice the bot can't log back in to the cloud server for 10 minutes
+// after logging out, and can't be launched on the same local server
+// again for 10 minutes after closing.
+func (b *Bot) MoveTo(url string, local bool) error {
+	var err error
+	if b.local {
+		_, err = b.Close()
+	} else {
+		_, err = b.Logout()
+	}
+	if err != nil {
+		return err
+	}
+
+	b.URL = url
+	b.local = local
+	return nil
+}
+
+// fileURI returns the file:// URI a local server reads the file by.
+func fileURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return "file://" + filepath.ToSlash(abs), nil
+}
+
+// attachLocal replaces the attach:// references to the local files,
+// e.g. within the media of an album, with the files URIs, since there
+// are no uploaded parts to attach.
+func attachLocal(params map[string]string, files map[string]string) {
+	if len(files) == 0 {
+		return
+	}
+
+	var oldnew []string
+	for name, uri := range files {
+		ref, _ := json.Marshal("attach://" + name)
+		val, _ := json.Marshal(uri)
+		oldnew = append(oldnew, string(ref), string(val))
+	}
+
+	r := strings.NewReplacer(oldnew...)
+	for k, v := range params {
+		params[k] = r.Replace(v)
+	}
+}
@@ -0,0 +1,107 @@
+package telebot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+	var (
+		methods []string
+		params  = make(map[string]map[string]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		methods = append(methods, method)
+
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var p map[string]string
+		json.NewDecoder(r.Body).Decode(&p)
+		params[method] = p
+
+		switch method {
+		case "getFile":
+			w.Write([]byte(`{"ok":true,"result":{"file_path":"` + path + `"}}`))
+		case "sendPhoto":
+			w.Write([]byte(`{"ok":true,"result":{"photo":[{"file_id":"id"}]}}`))
+		case "sendMediaGroup":
+			w.Write([]byte(`{"ok":true,"result":[{"photo":[{"file_id":"id"}]},{"photo":[{"file_id":"id"}]}]}`))
+		default:
+			w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true, Local: true})
+	require.NoError(t, err)
+	assert.EqualValues(t, LocalFileLimit, b.UploadLimit())
+
+	_, err = b.Send(&Chat{ID: 1}, &Photo{File: FromDisk(path)})
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+path, params["sendPhoto"]["photo"])
+
+	_, err = b.SendAlbum(&Chat{ID: 1}, Album{
+		&Photo{File: FromDisk(path)},
+		&Photo{File: FromDisk(path)},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, params["sendMediaGroup"]["media"], "attach://")
+	assert.Contains(t, params["sendMediaGroup"]["media"], `"media":"file://`+path+`"`)
+
+	r, err := b.File(&File{FileID: "id"})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "data", string(data))
+
+	_, err = b.File(&File{FileID: "id", FileSize: LocalFileLimit + 1})
+	assert.Equal(t, ErrFileTooBig, err)
+
+	require.NoError(t, b.MoveTo(srv.URL, false))
+	assert.EqualValues(t, CloudDownloadLimit, b.DownloadLimit())
+	require.NoError(t, b.MoveTo(srv.URL, true))
+	assert.Equal(t, []string{"sendPhoto", "sendMediaGroup", "getFile", "close", "logOut"}, methods)
+}
+
+func TestUploadLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	require.NoError(t, os.Truncate(path, CloudUploadLimit+1))
+
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		w.Write([]byte(`{"ok":true,"result":{"document":{"file_id":"id"}}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: FromDisk(path)})
+	assert.Equal(t, ErrFileTooBig, err)
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: File{
+		FileReader: strings.NewReader("data"),
+		FileSize:   CloudUploadLimit + 1,
+	}})
+	assert.Equal(t, ErrFileTooBig, err)
+	assert.Empty(t, methods)
+
+	// the local server takes larger files
+	b.local = true
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: FromDisk(path)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sendDocument"}, methods)
+}
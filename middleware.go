@@ -1,5 +1,7 @@
 package telebot
 
+import "regexp"
+
 // MiddlewareFunc represents a middleware processing function,
 // which get called before the endpoint group or specific handler.
 type MiddlewareFunc func(HandlerFunc) HandlerFunc
@@ -36,3 +38,15 @@ func (g *Group) Use(middleware ...MiddlewareFunc) {
 func (g *Group) Handle(endpoint interface{}, h HandlerFunc, m ...MiddlewareFunc) {
 	g.b.Handle(endpoint, h, appendMiddleware(g.middleware, m)...)
 }
+
+// HandleRegex adds the regexp handler to the bot, combining group's
+// middleware with the optional given middleware.
+func (g *Group) HandleRegex(rx *regexp.Regexp, h HandlerFunc, m ...MiddlewareFunc) {
+	g.b.HandleRegex(rx, h, appendMiddleware(g.middleware, m)...)
+}
+
+// HandleFunc adds the predicate handler to the bot, combining group's
+// middleware with the optional given middleware.
+func (g *Group) HandleFunc(pred func(Context) bool, h HandlerFunc, m ...MiddlewareFunc) {
+	g.b.HandleFunc(pred, h, appendMiddleware(g.middleware, m)...)
+}
@@ -0,0 +1,147 @@
+package telebot
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/telebot.v4/internal/atomicfile"
+)
+
+// OffsetStore persists the ID of the last processed update,
+// so LongPoller can continue from it after a restart.
+type OffsetStore interface {
+	// Offset returns the ID of the last processed update.
+	Offset() (int, error)
+
+	// SetOffset saves the ID of the last processed update.
+	SetOffset(id int) error
+}
+
+// MemoryOffsetStore is an in-memory OffsetStore.
+type MemoryOffsetStore struct {
+	mu sync.Mutex
+	id int
+}
+
+// Offset implements OffsetStore.
+func (s *MemoryOffsetStore) Offset() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id, nil
+}
+
+// SetOffset implements OffsetStore.
+func (s *MemoryOffsetStore) SetOffset(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id = id
+	return nil
+}
+
+// FileOffsetStore is an OffsetStore backed by a plain text file.
+type FileOffsetStore struct {
+	Path string
+}
+
+// NewFileOffsetStore returns an OffsetStore saving the offset to the file.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{Path: path}
+}
+
+// Offset implements OffsetStore.
+// It returns zero if the file doesn't exist yet.
+func (s *FileOffsetStore) Offset() (int, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, wrapError(err)
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return id, nil
+}
+
+// SetOffset implements OffsetStore.
+func (s *FileOffsetStore) SetOffset(id int) error {
+	if err := atomicfile.WriteFile(s.Path, []byte(strconv.Itoa(id))); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// offsetCommitter keeps track of the updates delivered by the poller
+// and commits the offset, once all the updates before it are processed.
+type offsetCommitter struct {
+	store   OffsetStore
+	onError func(error)
+
+	mu        sync.Mutex
+	committed int
+	delivered int
+	pending   map[int]struct{}
+	progress  chan struct{}
+}
+
+func newOffsetCommitter(store OffsetStore, offset int, onError func(error)) *offsetCommitter {
+	return &offsetCommitter{
+		store:     store,
+		onError:   onError,
+		committed: offset,
+		delivered: offset,
+		pending:   make(map[int]struct{}),
+		progress:  make(chan struct{}, 1),
+	}
+}
+
+func (oc *offsetCommitter) offset() int {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	return oc.committed
+}
+
+func (oc *offsetCommitter) deliver(id int) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	oc.pending[id] = struct{}{}
+	oc.delivered = id
+}
+
+func (oc *offsetCommitter) done(id int) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if _, ok := oc.pending[id]; !ok {
+		return
+	}
+	delete(oc.pending, id)
+
+	offset := oc.delivered
+	for id := range oc.pending {
+		if id-1 < offset {
+			offset = id - 1
+		}
+	}
+	if offset <= oc.committed {
+		return
+	}
+
+	if err := oc.store.SetOffset(offset); err != nil {
+		oc.onError(err)
+		return
+	}
+	oc.committed = offset
+
+	select {
+	case oc.progress <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,256 @@
+package telebot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileOffsetStore(t *testing.T) {
+	s := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+
+	id, err := s.Offset()
+	require.NoError(t, err)
+	assert.Zero(t, id)
+
+	require.NoError(t, s.SetOffset(42))
+	id, err = s.Offset()
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+}
+
+func TestOffsetCommitter(t *testing.T) {
+	store := &MemoryOffsetStore{}
+	oc := newOffsetCommitter(store, 10, func(err error) { t.Fatal(err) })
+
+	oc.deliver(11)
+	oc.deliver(12)
+	oc.deliver(14)
+
+	oc.done(12)
+	assert.Equal(t, 10, oc.offset())
+
+	// 13 is never delivered, so it's safe to skip it
+	oc.done(11)
+	assert.Equal(t, 13, oc.offset())
+
+	oc.done(14)
+	assert.Equal(t, 14, oc.offset())
+
+	id, _ := store.Offset()
+	assert.Equal(t, 14, id)
+
+	// unknown updates are ignored
+	oc.done(100)
+	assert.Equal(t, 14, oc.offset())
+}
+
+func TestLongPollerOffsets(t *testing.T) {
+	offsets := make(chan int, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+
+		offset, _ := strconv.Atoi(params["offset"])
+		offsets <- offset
+
+		var updates []Update
+		for id := offset; id <= 3; id++ {
+			updates = append(updates, Update{ID: id, Message: &Message{Text: "text"}})
+		}
+		if len(updates) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		data, _ := json.Marshal(updates)
+		w.Write([]byte(`{"ok":true,"result":` + string(data) + `}`))
+	}))
+	defer srv.Close()
+
+	store := &MemoryOffsetStore{}
+	require.NoError(t, store.SetOffset(1))
+
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Poller:  &LongPoller{Offsets: store},
+	})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	b.Handle(OnText, func(c Context) error {
+		if c.Update().ID == 2 {
+			<-release
+		}
+		return nil
+	})
+
+	go b.Start()
+	defer b.Stop()
+
+	// starts from the stored offset, and keeps the one
+	// of the unfinished update
+	assert.Equal(t, 2, <-offsets)
+	assert.Equal(t, 2, <-offsets)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		id, _ := store.Offset()
+		return id == 3
+	}, time.Second, time.Millisecond)
+}
+
+func TestLongPollerOffsetsBlocked(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+		offset, _ := strconv.Atoi(params["offset"])
+
+		// the second update comes later
+		last := 1
+		if atomic.AddInt32(&requests, 1) > 2 {
+			last = 2
+		}
+
+		var updates []Update
+		for id := offset; id <= last; id++ {
+			updates = append(updates, Update{ID: id, Message: &Message{Text: strconv.Itoa(id)}})
+		}
+		if len(updates) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		data, _ := json.Marshal(updates)
+		w.Write([]byte(`{"ok":true,"result":` + string(data) + `}`))
+	}))
+	defer srv.Close()
+
+	store := &MemoryOffsetStore{}
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Poller:  &LongPoller{Offsets: store},
+	})
+	require.NoError(t, err)
+
+	// the first handler waits for the second update
+	second := make(chan struct{})
+	done := make(chan struct{})
+	b.Handle(OnText, func(c Context) error {
+		switch c.Text() {
+		case "1":
+			select {
+			case <-second:
+				close(done)
+			case <-time.After(5 * time.Second):
+			}
+		case "2":
+			close(second)
+		}
+		return nil
+	})
+
+	go b.Start()
+	defer b.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second update is not received")
+	}
+
+	assert.Eventually(t, func() bool {
+		id, _ := store.Offset()
+		return id == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestLongPollerOffsetsFiltered(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+		offset, _ := strconv.Atoi(params["offset"])
+
+		var updates []Update
+		for id := offset; id <= 2; id++ {
+			updates = append(updates, Update{ID: id, Message: &Message{Text: "text"}})
+		}
+		if len(updates) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		data, _ := json.Marshal(updates)
+		w.Write([]byte(`{"ok":true,"result":` + string(data) + `}`))
+	}))
+	defer srv.Close()
+
+	store := &MemoryOffsetStore{}
+	lp := &LongPoller{Offsets: store}
+
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Poller: NewMiddlewarePoller(lp, func(u *Update) bool {
+			return u.ID != 1
+		}),
+	})
+	require.NoError(t, err)
+
+	handled := make(chan int, 10)
+	b.Handle(OnText, func(c Context) error {
+		handled <- c.Update().ID
+		return nil
+	})
+
+	go b.Start()
+	defer b.Stop()
+
+	assert.Equal(t, 2, <-handled)
+	assert.Eventually(t, func() bool {
+		id, _ := store.Offset()
+		return id == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestLongPollerOffsetsIdle(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"ok":true,"result":[{"update_id":1,"message":{"text":"text"}}]}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Poller: &LongPoller{
+			Offsets: &MemoryOffsetStore{},
+			Backoff: Backoff{Min: 10 * time.Millisecond},
+		},
+	})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	b.Handle(OnText, func(c Context) error {
+		<-release
+		return nil
+	})
+
+	go b.Start()
+	defer b.Stop()
+	defer close(release)
+
+	// the same batch is polled less and less often
+	// while the handler is in progress
+	time.Sleep(time.Second)
+	assert.Less(t, atomic.LoadInt32(&requests), int32(20))
+}
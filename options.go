@@ -37,6 +37,11 @@ const (
 
 	// IgnoreThread is used to ignore the thread when responding to a message via context.
 	IgnoreThread
+
+	// WebhookReply is used to send the message via context as a reply to the webhook
+	// request, saving a round trip. Only works with Webhook.Reply enabled.
+	// Telegram doesn't return the result then, so the sent message is nil.
+	WebhookReply
 )
 
 // Placeholder is used to set input field placeholder as a send option.
@@ -95,6 +100,9 @@ type SendOptions struct {
 
 	// Unique identifier of the message effect to be added to the message; for private chats only
 	EffectID string
+
+	// Progress reports the progress of the upload of the message files.
+	Progress ProgressFunc
 }
 
 func (og *SendOptions) copy() *SendOptions {
@@ -147,6 +155,8 @@ func (b *Bot) extractOptions(how []interface{}) *SendOptions {
 				opts.ReplyMarkup.RemoveKeyboard = true
 			case Protected:
 				opts.Protected = true
+			case IgnoreThread, WebhookReply:
+				// handled by the context
 			default:
 				panic("telebot: unsupported flag-option")
 			}
@@ -154,6 +164,10 @@ func (b *Bot) extractOptions(how []interface{}) *SendOptions {
 			opts.ParseMode = opt
 		case Entities:
 			opts.Entities = opt
+		case ProgressFunc:
+			opts.Progress = opt
+		case func(done, total int64):
+			opts.Progress = opt
 		default:
 			panic("telebot: unsupported send-option")
 		}
@@ -1,6 +1,12 @@
 package telebot
 
-import "time"
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
 
 var AllowedUpdates = []string{
 	"message",
@@ -61,10 +67,115 @@ type LongPoller struct {
 	// 		poll_answer
 	//
 	AllowedUpdates []string `yaml:"allowed_updates"`
+
+	// Backoff defines the delays between failed getUpdates requests.
+	Backoff Backoff `yaml:"backoff"`
+
+	// OnError is called on every failed getUpdates request.
+	// Defaults to the bot's OnError.
+	OnError func(error) `yaml:"-"`
+
+	// Offsets persists the ID of the last processed update. Once set,
+	// the poller starts from the stored offset and confirms updates
+	// to Telegram only after their handlers are finished, so the
+	// updates are delivered at least once across restarts.
+	Offsets OffsetStore `yaml:"-"`
+}
+
+// Backoff is an exponential backoff with jitter.
+type Backoff struct {
+	// Min is the delay after the first failure. Default: 100ms.
+	Min time.Duration `yaml:"min"`
+
+	// Max is the delay limit. Default: 1 minute.
+	Max time.Duration `yaml:"max"`
+
+	// Factor is the delay multiplier for each next failure. Default: 2.
+	Factor float64 `yaml:"factor"`
+
+	// Jitter is the fraction of the delay it's randomized by,
+	// in [0, 1] range. Default: 0.2.
+	Jitter float64 `yaml:"jitter"`
+}
+
+// Delay returns the delay after the given number
+// of consecutive failures, starting from 1.
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+
+	min, max := b.Min, b.Max
+	if min <= 0 {
+		min = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = time.Minute
+	}
+	factor := b.Factor
+	if factor < 1 {
+		factor = 2
+	}
+	jitter := b.Jitter
+	if jitter <= 0 || jitter > 1 {
+		jitter = 0.2
+	}
+
+	d := float64(min)
+	for i := 1; i < failures && d < float64(max); i++ {
+		d *= factor
+	}
+	if d > float64(max) {
+		d = float64(max)
+	}
+
+	d += d * jitter * (2*rand.Float64() - 1)
+	return time.Duration(d)
+}
+
+// isFatal tells whether getUpdates is never going to succeed.
+func isFatal(err error) bool {
+	return errors.Is(err, ErrUnauthorized) ||
+		errors.Is(err, ErrTerminatedByOtherGetUpdates) ||
+		errors.Is(err, ErrWebhookIsActive)
 }
 
 // Poll does long polling.
 func (p *LongPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
+	// Cancel the pending getUpdates request as soon as the poller
+	// is stopped, but not the other requests made by the bot.
+	ctx, cancel := context.WithCancel(b.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	b = b.WithContext(ctx)
+
+	onError := p.OnError
+	if onError == nil {
+		onError = func(err error) { b.OnError(err, nil) }
+	}
+
+	var committer *offsetCommitter
+	if p.Offsets != nil {
+		offset, err := p.Offsets.Offset()
+		if err != nil {
+			onError(err)
+		} else if offset > p.LastUpdateID {
+			p.LastUpdateID = offset
+		}
+
+		committer = newOffsetCommitter(p.Offsets, p.LastUpdateID, onError)
+		defer b.tracker.listen(committer.done)()
+	}
+
+	var failures, idle int
 	for {
 		select {
 		case <-stop:
@@ -72,15 +183,74 @@ func (p *LongPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		default:
 		}
 
-		updates, err := b.getUpdates(p.LastUpdateID+1, p.Limit, p.Timeout, p.AllowedUpdates)
+		offset := p.LastUpdateID
+		if committer != nil {
+			offset = committer.offset()
+		}
+
+		updates, err := b.getUpdates(offset+1, p.Limit, p.Timeout, p.AllowedUpdates)
 		if err != nil {
-			b.debug(err)
+			if ctx.Err() != nil {
+				return
+			}
+
+			onError(err)
+
+			if isFatal(err) {
+				// Stop waits for the poller to return,
+				// so it can't be called synchronously.
+				go b.Stop()
+				<-stop
+				return
+			}
+
+			failures++
+			select {
+			case <-stop:
+				return
+			case <-time.After(p.Backoff.Delay(failures)):
+			}
 			continue
 		}
+		failures = 0
 
+		var fresh int
 		for _, update := range updates {
+			// Updates are not confirmed until committed,
+			// so the ones in progress are received again.
+			if update.ID <= p.LastUpdateID {
+				continue
+			}
+
 			p.LastUpdateID = update.ID
+			if committer != nil {
+				committer.deliver(update.ID)
+			}
+
 			dest <- update
+			fresh++
+		}
+
+		// Give the handlers some time to catch up instead of fetching
+		// the same updates over and over again, but keep polling, since
+		// the handlers in progress may wait for the newer updates. The
+		// longer they're busy, the less often it's polled.
+		if committer != nil && len(updates) > 0 && fresh == 0 {
+			idle++
+			delay := p.Backoff.Delay(idle)
+			if p.Timeout > 0 && delay > p.Timeout {
+				delay = p.Timeout
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-committer.progress:
+				idle = 0
+			case <-time.After(delay):
+			}
+		} else {
+			idle = 0
 		}
 	}
 }
@@ -128,7 +298,91 @@ func (p *MiddlewarePoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		case upd := <-middle:
 			if p.Filter(&upd) {
 				dest <- upd
+			} else {
+				// the dropped update is never processed,
+				// so it mustn't hold the committed offset
+				b.tracker.skip(upd.ID)
 			}
 		}
 	}
 }
+
+// DedupStore remembers the IDs of the updates seen by DedupPoller.
+type DedupStore interface {
+	// Seen marks the update ID as seen and reports
+	// whether it has already been seen before.
+	Seen(id int) (bool, error)
+}
+
+// DedupPoller is a special kind of poller that drops the duplicate
+// updates, e.g. the ones redelivered by Telegram when the webhook
+// replies too slowly, so they never reach the handlers.
+type DedupPoller struct {
+	Poller Poller
+	Store  DedupStore // Default: NewMemoryDedupStore(1000)
+}
+
+// NewDedupPoller constructs a new deduplicating poller.
+func NewDedupPoller(original Poller) *DedupPoller {
+	return &DedupPoller{Poller: original}
+}
+
+// Poll filters out the updates with already seen IDs.
+// If the store fails, the update is passed through.
+func (p *DedupPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
+	if p.Store == nil {
+		p.Store = NewMemoryDedupStore(1000)
+	}
+
+	filter := func(u *Update) bool {
+		seen, err := p.Store.Seen(u.ID)
+		if err != nil {
+			b.OnError(err, nil)
+			return true
+		}
+		return !seen
+	}
+
+	NewMiddlewarePoller(p.Poller, filter).Poll(b, dest, stop)
+}
+
+// MemoryDedupStore is an in-memory DedupStore, which keeps
+// a bounded window of the most recently seen update IDs.
+type MemoryDedupStore struct {
+	mu   sync.Mutex
+	ids  []int
+	next int
+	seen map[int]struct{}
+}
+
+// NewMemoryDedupStore returns a store remembering up to size last IDs.
+func NewMemoryDedupStore(size int) *MemoryDedupStore {
+	if size < 1 {
+		size = 1
+	}
+	return &MemoryDedupStore{
+		ids:  make([]int, 0, size),
+		seen: make(map[int]struct{}, size),
+	}
+}
+
+// Seen implements DedupStore.
+func (s *MemoryDedupStore) Seen(id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.seen[id]; ok {
+		return true, nil
+	}
+
+	if len(s.ids) < cap(s.ids) {
+		s.ids = append(s.ids, id)
+	} else {
+		delete(s.seen, s.ids[s.next])
+		s.ids[s.next] = id
+		s.next = (s.next + 1) % len(s.ids)
+	}
+
+	s.seen[id] = struct{}{}
+	return false, nil
+}
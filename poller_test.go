@@ -1,9 +1,14 @@
 package telebot
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testPoller struct {
@@ -65,3 +70,103 @@ func TestMiddlewarePoller(t *testing.T) {
 	assert.Contains(t, ids, 1)
 	assert.Contains(t, ids, 2)
 }
+
+func TestLongPollerBackoff(t *testing.T) {
+	var bo Backoff
+	assert.Zero(t, bo.Delay(0))
+	assert.InDelta(t, 100*time.Millisecond, bo.Delay(1), float64(20*time.Millisecond))
+	assert.InDelta(t, 400*time.Millisecond, bo.Delay(3), float64(80*time.Millisecond))
+	assert.InDelta(t, time.Minute, bo.Delay(100), float64(12*time.Second))
+
+	bo = Backoff{Min: time.Second, Max: 3 * time.Second, Factor: 3, Jitter: 1}
+	for i := 0; i < 10; i++ {
+		assert.LessOrEqual(t, int64(bo.Delay(5)), int64(6*time.Second))
+	}
+}
+
+func TestLongPollerFatal(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	var errs []error
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Poller: &LongPoller{
+			Backoff: Backoff{Min: time.Millisecond},
+			OnError: func(err error) { errs = append(errs, err) },
+		},
+	})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		b.Start()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("bot is not stopped upon fatal error")
+	}
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, ErrUnauthorized, errs[2])
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	s := NewMemoryDedupStore(2)
+
+	seen := func(id int) bool {
+		ok, err := s.Seen(id)
+		require.NoError(t, err)
+		return ok
+	}
+
+	assert.False(t, seen(1))
+	assert.False(t, seen(2))
+	assert.True(t, seen(1))
+	assert.False(t, seen(3))
+
+	// 1 is out of the window now
+	assert.False(t, seen(1))
+	assert.True(t, seen(3))
+}
+
+func TestDedupPoller(t *testing.T) {
+	tp := newTestPoller()
+
+	b, err := NewBot(Settings{Offline: true, Synchronous: true})
+	require.NoError(t, err)
+	b.Poller = NewDedupPoller(tp)
+
+	var ids []int
+	b.Handle(OnText, func(c Context) error {
+		ids = append(ids, c.Update().ID)
+		if c.Update().ID == 3 {
+			tp.done <- struct{}{}
+		}
+		return nil
+	})
+
+	go func() {
+		for _, id := range []int{1, 2, 1, 2, 3} {
+			tp.updates <- Update{ID: id, Message: &Message{Text: "text"}}
+		}
+	}()
+
+	go b.Start()
+	<-tp.done
+	b.Stop()
+
+	assert.Equal(t, []int{1, 2, 3}, ids)
+}
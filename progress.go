@@ -0,0 +1,115 @@
+package telebot
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// ProgressFunc reports the progress of a file transfer: the number
+// of bytes transferred so far and the total size, or -1 if unknown.
+//
+// It can be set as File.OnProgress to track the upload or download of
+// a single file, or passed as a send option to track the upload of all
+// the files of a message as a whole, e.g. an album:
+//
+//		b.Send(chat, video, tele.ProgressFunc(func(done, total int64) {
+//			log.Printf("uploaded %d of %d bytes", done, total)
+//		}))
+//
+type ProgressFunc func(done, total int64)
+
+type progressKey struct{}
+
+// withProgress returns the bot reporting the progress of the uploads
+// to the function from the send options, if any.
+func (b *Bot) withProgress(opts *SendOptions) *Bot {
+	if opts == nil || opts.Progress == nil {
+		return b
+	}
+	return b.WithContext(context.WithValue(b.Context(), progressKey{}, opts.Progress))
+}
+
+// progressFrom returns the function passed with the send options.
+func progressFrom(ctx context.Context) ProgressFunc {
+	f, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return f
+}
+
+// progress accumulates the transferred bytes and reports them.
+type progress struct {
+	f     ProgressFunc
+	done  int64
+	total int64
+}
+
+func newProgress(f ProgressFunc, total int64) *progress {
+	if f == nil {
+		return nil
+	}
+	return &progress{f: f, total: total}
+}
+
+func (p *progress) add(n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.done += int64(n)
+	p.f(p.done, p.total)
+}
+
+// progressWriter reports the bytes written to w.
+type progressWriter struct {
+	w  io.Writer
+	ps []*progress
+}
+
+func (pw progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	for _, p := range pw.ps {
+		p.add(n)
+	}
+	return n, err
+}
+
+// progressReader reports the bytes read from the file.
+type progressReader struct {
+	io.ReadCloser
+	p *progress
+}
+
+func (pr progressReader) Read(b []byte) (int, error) {
+	n, err := pr.ReadCloser.Read(b)
+	pr.p.add(n)
+	return n, err
+}
+
+// trackDownload wraps the downloaded file body to report
+// the progress to OnProgress, if it's set.
+func (f *File) trackDownload(body io.ReadCloser, done, total int64) io.ReadCloser {
+	if f.OnProgress == nil {
+		return body
+	}
+	if total <= 0 {
+		total = -1
+	}
+	p := newProgress(f.OnProgress, total)
+	p.done = done
+	return progressReader{ReadCloser: body, p: p}
+}
+
+// uploadSize returns the size of the file to upload, or -1 if unknown.
+func uploadSize(f File, file interface{}) int64 {
+	switch r := file.(type) {
+	case string:
+		if fi, err := os.Stat(r); err == nil {
+			return fi.Size()
+		}
+	case interface{ Len() int }:
+		return int64(r.Len())
+	}
+	if f.FileSize > 0 {
+		return f.FileSize
+	}
+	return -1
+}
@@ -0,0 +1,54 @@
+package telebot
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getFile"):
+			w.Write([]byte(`{"ok":true,"result":{"file_path":"file"}}`))
+		case strings.Contains(r.URL.Path, "/file/"):
+			w.Write([]byte("downloaded"))
+		default:
+			w.Write([]byte(`{"ok":true,"result":{"document":{"file_id":"id"}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{URL: srv.URL, Offline: true})
+	require.NoError(t, err)
+
+	var file, overall [2]int64
+	doc := &Document{File: FromReader(bytes.NewReader([]byte("data")))}
+	doc.OnProgress = func(done, total int64) {
+		file = [2]int64{done, total}
+	}
+
+	_, err = b.Send(&Chat{ID: 1}, doc, ProgressFunc(func(done, total int64) {
+		overall = [2]int64{done, total}
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, [2]int64{4, 4}, file)
+	assert.Equal(t, [2]int64{4, 4}, overall)
+
+	var download [2]int64
+	r, err := b.File(&File{FileID: "id", OnProgress: func(done, total int64) {
+		download = [2]int64{done, total}
+	}})
+	require.NoError(t, err)
+	io.Copy(io.Discard, r)
+	r.Close()
+	assert.Equal(t, [2]int64{10, 10}, download)
+}
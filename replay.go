@@ -0,0 +1,178 @@
+package telebot
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// RecordedUpdate is a line of the updates recording,
+// the update with the time it has been received at.
+type RecordedUpdate struct {
+	Time   time.Time `json:"time"`
+	Update Update    `json:"update"`
+}
+
+// RecordingPoller is a special kind of poller that writes every
+// update it receives to Writer as JSON lines, so the traffic can
+// be replayed later with ReplayPoller, e.g. to reproduce a bug.
+//
+// Example:
+//
+//		f, err := os.OpenFile("updates.jsonl", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer f.Close()
+//
+//		pref.Poller = tele.NewRecordingPoller(pref.Poller, f)
+//
+type RecordingPoller struct {
+	Poller Poller
+	Writer io.Writer
+
+	// OnError is called when the update can't be recorded,
+	// the update is passed through anyway.
+	// Defaults to the bot's OnError.
+	OnError func(error)
+
+	mu sync.Mutex
+}
+
+// NewRecordingPoller constructs a new poller recording the updates to w.
+func NewRecordingPoller(original Poller, w io.Writer) *RecordingPoller {
+	return &RecordingPoller{
+		Poller: original,
+		Writer: w,
+	}
+}
+
+// Poll records the updates before passing them to the bot.
+func (p *RecordingPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
+	onError := p.OnError
+	if onError == nil {
+		onError = func(err error) { b.OnError(err, nil) }
+	}
+
+	filter := func(u *Update) bool {
+		if err := p.record(*u); err != nil {
+			onError(err)
+		}
+		return true
+	}
+
+	NewMiddlewarePoller(p.Poller, filter).Poll(b, dest, stop)
+}
+
+func (p *RecordingPoller) record(u Update) error {
+	data, err := json.Marshal(RecordedUpdate{
+		Time:   time.Now(),
+		Update: u,
+	})
+	if err != nil {
+		return wrapError(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the line is written at once, so it's never interleaved
+	if _, err := p.Writer.Write(append(data, '\n')); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// ReplayPoller is a poller feeding the updates recorded with
+// RecordingPoller back to the bot, e.g. the offline one or the one
+// talking to a fake server, so the recorded traffic is reproduced.
+type ReplayPoller struct {
+	Reader io.Reader
+
+	// Speed is the pace of the replay relative to the original one,
+	// e.g. 1 replays the updates with the recorded delays between
+	// them, and 2 twice as fast. Default: 0, as fast as possible.
+	Speed float64
+
+	// OnError is called when the recording can't be read.
+	// Defaults to the bot's OnError.
+	OnError func(error)
+}
+
+// NewReplayPoller constructs a new poller replaying the updates from r
+// as fast as possible.
+func NewReplayPoller(r io.Reader) *ReplayPoller {
+	return &ReplayPoller{Reader: r}
+}
+
+// Poll replays the updates. Once the recording is over,
+// the poller waits to be stopped.
+func (p *ReplayPoller) Poll(b *Bot, dest chan Update, stop chan struct{}) {
+	err := p.replay(stop, func(u Update) bool {
+		select {
+		case dest <- u:
+			return true
+		case <-stop:
+			return false
+		}
+	})
+	if err != nil {
+		if p.OnError != nil {
+			p.OnError(err)
+		} else {
+			b.OnError(err, nil)
+		}
+	}
+
+	<-stop
+}
+
+// Replay processes the recorded updates with the bot one by one,
+// without starting it. It returns once the recording is over, or the
+// bot's context is done. Notice the handlers are still run concurrently,
+// unless the bot is Synchronous.
+func (p *ReplayPoller) Replay(b *Bot) error {
+	done := b.Context().Done()
+	return p.replay(done, func(u Update) bool {
+		b.ProcessUpdate(u)
+		return true
+	})
+}
+
+// replay reads the recording and passes the updates to f, keeping
+// the pace, until f returns false or done is closed.
+func (p *ReplayPoller) replay(done <-chan struct{}, f func(Update) bool) error {
+	dec := json.NewDecoder(p.Reader)
+
+	var prev time.Time
+	for {
+		var rec RecordedUpdate
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return wrapError(err)
+		}
+
+		if p.Speed > 0 && !prev.IsZero() && rec.Time.After(prev) {
+			delay := time.Duration(float64(rec.Time.Sub(prev)) / p.Speed)
+			select {
+			case <-done:
+				return nil
+			case <-time.After(delay):
+			}
+		}
+		prev = rec.Time
+
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+		if !f(rec.Update) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package telebot
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingPoller(t *testing.T) {
+	tp := newTestPoller()
+
+	var buf bytes.Buffer
+	b, err := NewBot(Settings{Offline: true, Synchronous: true})
+	require.NoError(t, err)
+	b.Poller = NewRecordingPoller(tp, &buf)
+
+	b.Handle(OnCallback, func(c Context) error {
+		tp.done <- struct{}{}
+		return nil
+	})
+
+	go func() {
+		tp.updates <- Update{ID: 1, Message: &Message{ID: 10, Text: "/start"}}
+		tp.updates <- Update{ID: 2, Callback: &Callback{ID: "cb", Data: "\fbtn|data"}}
+	}()
+
+	go b.Start()
+	<-tp.done
+	b.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var rec RecordedUpdate
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	assert.Equal(t, 2, rec.Update.ID)
+	assert.Equal(t, "\fbtn|data", rec.Update.Callback.Data)
+	assert.WithinDuration(t, time.Now(), rec.Time, time.Minute)
+}
+
+func TestReplayPoller(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+	for i, text := range []string{"/start", "hello", "/stop"} {
+		data, err := json.Marshal(RecordedUpdate{
+			Time:   start.Add(time.Duration(i) * 50 * time.Millisecond),
+			Update: Update{ID: i + 1, Message: &Message{Text: text}},
+		})
+		require.NoError(t, err)
+		buf.Write(append(data, '\n'))
+	}
+	recording := buf.String()
+
+	t.Run("Replay", func(t *testing.T) {
+		b, err := NewBot(Settings{Offline: true, Synchronous: true})
+		require.NoError(t, err)
+
+		var texts []string
+		b.Handle(OnText, func(c Context) error {
+			texts = append(texts, c.Text())
+			return nil
+		})
+		b.Handle("/start", func(c Context) error {
+			texts = append(texts, c.Text())
+			return nil
+		})
+
+		p := NewReplayPoller(strings.NewReader(recording))
+		require.NoError(t, p.Replay(b))
+		assert.Equal(t, []string{"/start", "hello", "/stop"}, texts)
+	})
+
+	t.Run("Poll", func(t *testing.T) {
+		b, err := NewBot(Settings{Offline: true, Synchronous: true})
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		b.Handle("/stop", func(c Context) error {
+			close(done)
+			return nil
+		})
+
+		b.Poller = &ReplayPoller{Reader: strings.NewReader(recording), Speed: 1}
+
+		started := time.Now()
+		go b.Start()
+		<-done
+		b.Stop()
+
+		assert.GreaterOrEqual(t, time.Since(started), 100*time.Millisecond)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		b, err := NewBot(Settings{Offline: true})
+		require.NoError(t, err)
+
+		p := NewReplayPoller(strings.NewReader("{}\nnot json\n"))
+		assert.Error(t, p.Replay(b))
+	})
+}
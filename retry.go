@@ -0,0 +1,145 @@
+package telebot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// RetryPolicy retries the requests failed with transient errors:
+// network errors, 5xx server errors and flood errors (429). It covers
+// both JSON and multipart requests, the latter ones are retried only
+// if all of their files can be read again, see File.
+//
+// Notice the request failed with a network error may still have been
+// processed by Telegram, so retrying it may duplicate a message.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts. Default: 3.
+	Attempts int `yaml:"attempts"`
+
+	// Backoff defines the delays between attempts failed with
+	// network and server errors. Flood errors are retried after
+	// the delay Telegram asks to wait.
+	Backoff Backoff `yaml:"backoff"`
+
+	// MaxWait limits the delay of the flood errors, the ones
+	// with a longer delay are returned immediately. Zero means
+	// there's no limit.
+	MaxWait time.Duration `yaml:"max_wait"`
+}
+
+func (p *RetryPolicy) attempts() int {
+	if p.Attempts <= 0 {
+		return 3
+	}
+	return p.Attempts
+}
+
+// delay returns the delay before the next attempt after the given
+// number of failures, and whether the error should be retried at all.
+func (p *RetryPolicy) delay(err error, failures int) (time.Duration, bool) {
+	var (
+		flood  FloodError
+		apiErr *Error
+		netErr net.Error
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		// the request is cancelled on purpose, e.g. by Stop,
+		// and it satisfies net.Error, so it's checked first
+		return 0, false
+	case errors.As(err, &flood):
+		d := time.Duration(flood.RetryAfter) * time.Second
+		if p.MaxWait > 0 && d > p.MaxWait {
+			return 0, false
+		}
+		return d, true
+	case errors.As(err, &apiErr):
+		return p.Backoff.Delay(failures), apiErr.Code >= 500
+	case errors.As(err, &netErr),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return p.Backoff.Delay(failures), true
+	default:
+		return 0, false
+	}
+}
+
+// retried calls f according to the bot's retry policy. The file
+// readers are rewound before each retry.
+func (b *Bot) retried(files map[string]File, f func() ([]byte, error)) ([]byte, error) {
+	if b.retry == nil {
+		return f()
+	}
+
+	rewind, ok := rewinder(files)
+	if !ok {
+		return f()
+	}
+
+	// the stop channel is reset once closed,
+	// so it's taken before the first attempt
+	ctx, stopped := b.Context(), b.stopClient.done()
+	for failures := 1; ; failures++ {
+		data, err := f()
+		if err == nil || failures >= b.retry.attempts() || ctx.Err() != nil {
+			return data, err
+		}
+
+		d, retry := b.retry.delay(err, failures)
+		if !retry {
+			return data, err
+		}
+
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return data, err
+		case <-stopped:
+			return data, err
+		}
+
+		if err := rewind(); err != nil {
+			return nil, wrapError(err)
+		}
+	}
+}
+
+// rewinder returns a function rewinding the readers of the files to
+// their current positions. It reports false if some reader is neither
+// an io.Seeker nor can be opened again.
+func rewinder(files map[string]File) (func() error, bool) {
+	type position struct {
+		s   io.Seeker
+		off int64
+	}
+
+	var positions []position
+	for _, f := range files {
+		if f.FileReader == nil || f.InCloud() || f.FileURL != "" || f.OnDisk() || f.FileOpener != nil {
+			continue
+		}
+
+		s, ok := f.FileReader.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+		off, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		positions = append(positions, position{s: s, off: off})
+	}
+
+	return func() error {
+		for _, p := range positions {
+			if _, err := p.s.Seek(p.off, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, true
+}
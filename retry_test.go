@@ -0,0 +1,153 @@
+package telebot
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := &RetryPolicy{MaxWait: time.Second}
+
+	d, ok := p.delay(FloodError{err: NewError(429), RetryAfter: 1}, 1)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, d)
+
+	_, ok = p.delay(FloodError{err: NewError(429), RetryAfter: 2}, 1)
+	assert.False(t, ok)
+
+	_, ok = p.delay(ErrInternal, 1)
+	assert.True(t, ok)
+	_, ok = p.delay(NewError(502, "Bad Gateway"), 1)
+	assert.True(t, ok)
+	_, ok = p.delay(ErrChatNotFound, 1)
+	assert.False(t, ok)
+}
+
+func TestRetry(t *testing.T) {
+	var (
+		failures int
+		uploads  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			uploads = append(uploads, r.FormValue("document"))
+		} else {
+			io.Copy(io.Discard, r.Body)
+		}
+
+		switch {
+		case failures == 0:
+			failures++
+			w.WriteHeader(http.StatusBadGateway)
+		case failures == 1:
+			failures++
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`))
+		default:
+			failures = 0
+			w.Write([]byte(`{"ok":true,"result":{"text":"ok"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Retry:   &RetryPolicy{Backoff: Backoff{Min: time.Millisecond}},
+	})
+	require.NoError(t, err)
+
+	msg, err := b.Send(&Chat{ID: 1}, "text")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", msg.Text)
+
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: FromReader(bytes.NewReader([]byte("data")))})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data", "data", "data"}, uploads)
+
+	uploads = nil
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: FromOpener(func() (io.Reader, error) {
+		return strings.NewReader("opened"), nil
+	})})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"opened", "opened", "opened"}, uploads)
+
+	// plain readers can't be read again
+	uploads = nil
+	_, err = b.Send(&Chat{ID: 1}, &Document{File: FromReader(io.LimitReader(strings.NewReader("data"), 4))})
+	assert.Equal(t, NewError(502, "Bad Gateway"), err)
+	assert.Equal(t, []string{"data"}, uploads)
+}
+
+func TestRetryLimiter(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`))
+	}))
+	defer srv.Close()
+
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Limiter: &Limiter{Global: 1000, Private: 1000},
+		Retry:   &RetryPolicy{},
+	})
+	require.NoError(t, err)
+
+	// the flood errors are retried by the policy only
+	_, err = b.Send(&Chat{ID: 1}, "text")
+	assert.Error(t, err)
+	assert.Equal(t, 3, requests)
+}
+
+func TestRetryStop(t *testing.T) {
+	var requests int32
+	received, release := make(chan struct{}, 10), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		received <- struct{}{}
+		select {
+		case <-time.After(2 * time.Second):
+		case <-release:
+		}
+		w.Write([]byte(`{"ok":true,"result":{"text":"ok"}}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	b, err := NewBot(Settings{
+		URL:     srv.URL,
+		Offline: true,
+		Poller:  newTestPoller(),
+		Retry:   &RetryPolicy{Backoff: Backoff{Min: time.Millisecond}},
+	})
+	require.NoError(t, err)
+
+	go b.Start()
+	require.Eventually(t, func() bool {
+		return b.stopClient.done() != nil
+	}, time.Second, time.Millisecond)
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := b.Send(&Chat{ID: 1}, "text")
+		sent <- err
+	}()
+	<-received
+
+	// the request cancelled by Stop is not sent again
+	start := time.Now()
+	b.Stop()
+	assert.Error(t, <-sent)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
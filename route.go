@@ -0,0 +1,112 @@
+package telebot
+
+import (
+	"errors"
+	"regexp"
+)
+
+const matchesKey = "telebot.matches"
+
+// patternHandler is the handler of the texts matching the regexp,
+// or of the updates satisfying the predicate.
+type patternHandler struct {
+	rx   *regexp.Regexp
+	pred func(Context) bool
+	h    HandlerFunc
+
+	// middleware is the bot middleware, which is applied
+	// once around all the handlers the update goes through
+	middleware []MiddlewareFunc
+}
+
+// HandleRegex sets the handler for the texts matching the regexp: the
+// text messages, and the data of the callbacks, without the leading \f
+// of the unhandled buttons. The captured groups are available with
+// Context.Matches.
+//
+// The pattern handlers, set by either HandleRegex or HandleFunc, are
+// tried in the order they are set, once there is no exact handler for
+// the text or the button, before the OnText and OnCallback ones. The
+// handler may return ErrSkip to pass the update to the next matching
+// pattern handler, and then to the OnText or OnCallback one.
+//
+// Example:
+//
+//	b.HandleRegex(regexp.MustCompile(`^#(\d{6})$`), func(c tele.Context) error {
+//		return showOrder(c, c.Matches()[1])
+//	})
+func (b *Bot) HandleRegex(rx *regexp.Regexp, h HandlerFunc, m ...MiddlewareFunc) {
+	b.handlePatterns(patternHandler{rx: rx}, h, m)
+}
+
+// HandleFunc sets the handler for the text messages and the callbacks
+// satisfying the predicate, see HandleRegex for the order they are
+// tried in. The predicates are called synchronously, so they should
+// be fast.
+//
+// Example:
+//
+//	b.HandleFunc(func(c tele.Context) bool {
+//		return c.Callback() != nil && strings.HasPrefix(c.Data(), "page:")
+//	}, onPage)
+func (b *Bot) HandleFunc(pred func(Context) bool, h HandlerFunc, m ...MiddlewareFunc) {
+	b.handlePatterns(patternHandler{pred: pred}, h, m)
+}
+
+func (b *Bot) handlePatterns(p patternHandler, h HandlerFunc, m []MiddlewareFunc) {
+	p.h = applyMiddleware(h, m...)
+	p.middleware = b.group.middleware
+	b.patterns = append(b.patterns, p)
+}
+
+// handlePattern runs the first pattern handler matching the text,
+// it returns false if there is none. If all the matching handlers
+// skip the update, it's passed to the handlers of the fallback
+// endpoints, the ones it would be handled by with no patterns.
+func (b *Bot) handlePattern(c Context, text string, fallback ...string) bool {
+	i, matches := b.matchPattern(c, text, 0)
+	if i < 0 {
+		return false
+	}
+
+	m := b.patterns[i].middleware
+	b.runHandler(applyMiddleware(func(c Context) error {
+		for i >= 0 {
+			c.Set(matchesKey, matches)
+			err := b.patterns[i].h(c)
+			if !errors.Is(err, ErrSkip) {
+				return err
+			}
+			i, matches = b.matchPattern(c, text, i+1)
+		}
+
+		c.Set(matchesKey, nil)
+		for _, end := range fallback {
+			if h, ok := b.chains[end]; ok {
+				if err := h(c); err != nil {
+					b.OnError(err, c)
+				}
+			}
+		}
+		return nil
+	}, m...), c)
+	return true
+}
+
+// matchPattern returns the index of the first pattern handler
+// matching the text, starting from the given one, and the captured
+// groups. It returns -1 if there is none.
+func (b *Bot) matchPattern(c Context, text string, from int) (int, []string) {
+	for i := from; i < len(b.patterns); i++ {
+		p := b.patterns[i]
+		switch {
+		case p.rx != nil:
+			if matches := p.rx.FindStringSubmatch(text); matches != nil {
+				return i, matches
+			}
+		case p.pred(c):
+			return i, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,146 @@
+package telebot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotHandleRegex(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var got []string
+	log := func(name string) HandlerFunc {
+		return func(c Context) error {
+			got = append(got, name+":"+strings.Join(c.Matches(), ","))
+			return nil
+		}
+	}
+
+	btn := InlineButton{Unique: "btn"}
+	b.Handle("/start", log("start"))
+	b.Handle("exact", log("exact"))
+	b.Handle(&btn, log("btn"))
+	b.Handle(OnText, log("text"))
+	b.Handle(OnCallback, log("callback"))
+
+	b.HandleRegex(regexp.MustCompile(`^#(\d+)$`), log("order"))
+	b.HandleRegex(regexp.MustCompile(`^page\|(\d+)$`), log("page"))
+	b.HandleRegex(regexp.MustCompile(`^#(\d+)`), log("order prefix"))
+	b.HandleFunc(func(c Context) bool {
+		return c.Callback() != nil && c.Callback().Data == "raw"
+	}, log("raw"))
+
+	for _, text := range []string{"/start", "exact", "#123", "#123 x", "hello"} {
+		b.ProcessUpdate(Update{Message: &Message{Text: text}})
+	}
+	for _, data := range []string{"\fbtn|1", "\fpage|2", "raw", "other"} {
+		b.ProcessUpdate(Update{Callback: &Callback{Data: data}})
+	}
+
+	assert.Equal(t, []string{
+		"start:",
+		"exact:",
+		"order:#123,123",
+		"order prefix:#123,123",
+		"text:",
+		"btn:",
+		"page:page|2,2",
+		"raw:",
+		"callback:",
+	}, got)
+}
+
+func TestBotHandleRegexSkip(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var got []string
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "text")
+		return nil
+	})
+
+	g := b.Group()
+	g.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if c.Sender() == nil {
+				return ErrSkip
+			}
+			return next(c)
+		}
+	})
+	g.HandleRegex(regexp.MustCompile(`^\d+$`), func(c Context) error {
+		got = append(got, "first")
+		return nil
+	})
+	b.HandleFunc(func(c Context) bool { return true }, func(c Context) error {
+		got = append(got, "second:"+strings.Join(c.Matches(), ","))
+		return nil
+	})
+
+	b.ProcessUpdate(Update{Message: &Message{Text: "1", Sender: &User{ID: 1}}})
+	b.ProcessUpdate(Update{Message: &Message{Text: "2"}})
+
+	assert.Equal(t, []string{"first", "second:"}, got)
+}
+
+func TestBotHandleRegexFallback(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var got []string
+	log := func(name string) HandlerFunc {
+		return func(c Context) error {
+			got = append(got, name+":"+strings.Join(c.Matches(), ","))
+			return nil
+		}
+	}
+
+	b.Handle(OnText, log("text"))
+	b.Handle(OnReply, log("reply"))
+	b.Handle(OnCallback, log("callback"))
+
+	skip := func(c Context) error { return ErrSkip }
+	b.HandleRegex(regexp.MustCompile(`^(\d+)$`), skip)
+	b.HandleFunc(func(c Context) bool { return true }, skip)
+
+	// all the matching handlers skip the updates
+	b.ProcessUpdate(Update{Message: &Message{Text: "1"}})
+	b.ProcessUpdate(Update{Message: &Message{Text: "2", ReplyTo: &Message{}}})
+	b.ProcessUpdate(Update{Callback: &Callback{Data: "3"}})
+
+	assert.Equal(t, []string{"text:", "reply:", "text:", "callback:"}, got)
+}
+
+func TestBotHandleRegexMiddleware(t *testing.T) {
+	b, err := NewBot(Settings{Synchronous: true, Offline: true})
+	require.NoError(t, err)
+
+	var got []string
+	b.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			got = append(got, "bot")
+			return next(c)
+		}
+	})
+
+	b.Handle(OnText, func(c Context) error {
+		got = append(got, "text")
+		return nil
+	})
+	for i := 0; i < 2; i++ {
+		b.HandleRegex(regexp.MustCompile(`^\d+$`), func(c Context) error {
+			got = append(got, "skip")
+			return ErrSkip
+		})
+	}
+
+	// the bot middleware runs once for the patterns and the fallback
+	b.ProcessUpdate(Update{Message: &Message{Text: "1"}})
+	assert.Equal(t, []string{"bot", "skip", "skip", "text"}, got)
+}
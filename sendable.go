@@ -31,7 +31,7 @@ func (p *Photo) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 	b.embedSendOptions(params, opt)
 
 	msg, err := b.sendMedia(p, params, nil)
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -58,7 +58,7 @@ func (a *Audio) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 	}
 
 	msg, err := b.sendMedia(a, params, thumbnailToFilemap(a.Thumbnail))
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -93,7 +93,7 @@ func (d *Document) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error
 	}
 
 	msg, err := b.sendMedia(d, params, thumbnailToFilemap(d.Thumbnail))
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -120,7 +120,7 @@ func (s *Sticker) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error)
 	b.embedSendOptions(params, opt)
 
 	msg, err := b.sendMedia(s, params, nil)
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -153,7 +153,7 @@ func (v *Video) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 	}
 
 	msg, err := b.sendMedia(v, params, thumbnailToFilemap(v.Thumbnail))
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -198,7 +198,7 @@ func (a *Animation) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, erro
 	}
 
 	msg, err := b.sendMedia(a, params, thumbnailToFilemap(a.Thumbnail))
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -231,7 +231,7 @@ func (v *Voice) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, error) {
 	}
 
 	msg, err := b.sendMedia(v, params, nil)
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -256,7 +256,7 @@ func (v *VideoNote) Send(b *Bot, to Recipient, opt *SendOptions) (*Message, erro
 	}
 
 	msg, err := b.sendMedia(v, params, thumbnailToFilemap(v.Thumbnail))
-	if err != nil {
+	if err != nil || msg == nil {
 		return nil, err
 	}
 
@@ -0,0 +1,169 @@
+// Package session provides the middleware keeping the typed sessions
+// of the users and chats, which live across the updates, unlike the
+// values set with Context.Set.
+//
+// Example:
+//
+//		type Cart struct {
+//			Items []string `json:"items"`
+//		}
+//
+//		b.Use(session.Middleware[Cart](session.Config{
+//			Storage: session.NewFileStorage("sessions"),
+//		}))
+//
+//		b.Handle("/add", func(c tele.Context) error {
+//			cart := session.Get[Cart](c)
+//			cart.Items = append(cart.Items, c.Message().Payload)
+//			return c.Send("Added!")
+//		})
+//
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// contextKey is the key of the session of the type in the context,
+// so the sessions of different types don't overwrite each other.
+func contextKey[T any]() string {
+	return fmt.Sprintf("session.%T", (*T)(nil))
+}
+
+// Scope defines what the session is kept for.
+type Scope int
+
+const (
+	// ByUser keeps the session of the user across all the chats.
+	ByUser Scope = iota
+
+	// ByChat keeps the session of the chat shared by its members.
+	ByChat
+
+	// ByUserChat keeps the session of the user in the chat.
+	ByUserChat
+)
+
+// Config defines config for Middleware.
+type Config struct {
+	// Storage persists the sessions. Default: NewMemoryStorage().
+	Storage Storage
+
+	// Scope defines the key of the session. Default: ByUser.
+	Scope Scope
+
+	// Key overrides the key of the session defined by Scope.
+	// The updates with empty key get a new session, which
+	// is not saved.
+	Key func(c tele.Context) string
+}
+
+// Middleware returns a middleware loading the session of type T
+// before the handler and saving it afterwards, if it's changed and
+// the handler succeeded. If the session has been saved by another
+// handler meanwhile, ErrConflict is returned, so the concurrent
+// changes are never lost.
+//
+// The session is encoded as JSON, so only the exported fields of T
+// are kept. It's stored by the key prefixed with the name of T, e.g.
+// "main.Cart:user:1", so the sessions of different types can share
+// the storage.
+func Middleware[T any](v Config) tele.MiddlewareFunc {
+	if v.Storage == nil {
+		v.Storage = NewMemoryStorage()
+	}
+	if v.Key == nil {
+		v.Key = v.Scope.key
+	}
+
+	// the sessions of different types may share the storage
+	prefix := reflect.TypeOf((*T)(nil)).Elem().String() + ":"
+
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			key := v.Key(c)
+			if key == "" {
+				c.Set(contextKey[T](), new(T))
+				return next(c)
+			}
+			key = prefix + key
+
+			data, version, err := v.Storage.Load(key)
+			if err != nil {
+				return fmt.Errorf("session: %s: %w", key, err)
+			}
+
+			s := new(T)
+			if data == nil {
+				// comparing with the empty session, so it's not saved
+				// until the handler changes it
+				data, err = json.Marshal(s)
+			} else {
+				err = json.Unmarshal(data, s)
+			}
+			if err != nil {
+				return fmt.Errorf("session: %s: %w", key, err)
+			}
+
+			c.Set(contextKey[T](), s)
+			if err := next(c); err != nil {
+				return err
+			}
+
+			changed, err := json.Marshal(s)
+			if err != nil {
+				return fmt.Errorf("session: %s: %w", key, err)
+			}
+			if bytes.Equal(changed, data) {
+				return nil
+			}
+
+			if err := v.Storage.Save(key, changed, version); err != nil {
+				return fmt.Errorf("session: %s: %w", key, err)
+			}
+			return nil
+		}
+	}
+}
+
+// Get returns the session of type T loaded by the middleware.
+// The changes of the session are saved once the handler returns.
+// It panics if the middleware of the same type is not used.
+func Get[T any](c tele.Context) *T {
+	s, ok := c.Get(contextKey[T]()).(*T)
+	if !ok {
+		panic(fmt.Sprintf("session: no session of type %T, middleware is not used", s))
+	}
+	return s
+}
+
+// key returns the key of the session in the scope,
+// or empty string if the context has no user or chat.
+func (s Scope) key(c tele.Context) string {
+	user, chat := c.Sender(), c.Chat()
+
+	switch s {
+	case ByChat:
+		if chat == nil {
+			return ""
+		}
+		return "chat:" + strconv.FormatInt(chat.ID, 10)
+	case ByUserChat:
+		if chat == nil || user == nil {
+			return ""
+		}
+		return "chat:" + strconv.FormatInt(chat.ID, 10) +
+			":user:" + strconv.FormatInt(user.ID, 10)
+	default:
+		if user == nil {
+			return ""
+		}
+		return "user:" + strconv.FormatInt(user.ID, 10)
+	}
+}
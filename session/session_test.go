@@ -0,0 +1,174 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+	"gopkg.in/telebot.v4/teletest"
+)
+
+type counter struct {
+	N int `json:"n"`
+}
+
+type countingStorage struct {
+	Storage
+	saves int
+}
+
+func (s *countingStorage) Save(key string, data []byte, version int64) error {
+	s.saves++
+	return s.Storage.Save(key, data, version)
+}
+
+func TestMiddleware(t *testing.T) {
+	storage := &countingStorage{Storage: NewMemoryStorage()}
+	rec := &teletest.Recorder{}
+
+	var n int
+	h := Middleware[counter](Config{Storage: storage})(func(c tele.Context) error {
+		s := Get[counter](c)
+		if c.Text() == "inc" {
+			s.N++
+		}
+		n = s.N
+		return nil
+	})
+
+	alice := &tele.User{ID: 1}
+	bob := &tele.User{ID: 2}
+
+	require.NoError(t, h(rec.NewContext(teletest.NewUpdate().From(alice).Text("inc"))))
+	require.NoError(t, h(rec.NewContext(teletest.NewUpdate().From(alice).Text("inc"))))
+	assert.Equal(t, 2, n)
+
+	require.NoError(t, h(rec.NewContext(teletest.NewUpdate().From(alice).Text("get"))))
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, storage.saves)
+
+	require.NoError(t, h(rec.NewContext(teletest.NewUpdate().From(bob).Text("get"))))
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 2, storage.saves)
+
+	data, version, err := storage.Load("session.counter:user:1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"n":2}`, string(data))
+	assert.Equal(t, int64(2), version)
+}
+
+func TestMiddlewareTypes(t *testing.T) {
+	type profile struct {
+		Name string `json:"name"`
+	}
+
+	storage := NewMemoryStorage()
+	rec := &teletest.Recorder{}
+
+	m1 := Middleware[counter](Config{Storage: storage})
+	m2 := Middleware[profile](Config{Storage: storage})
+	h := m1(m2(func(c tele.Context) error {
+		Get[counter](c).N++
+		Get[profile](c).Name = "alice"
+		return nil
+	}))
+
+	// the sessions of different types are kept apart
+	require.NoError(t, h(rec.NewContext(teletest.NewUpdate().Text("inc"))))
+	require.NoError(t, h(rec.NewContext(teletest.NewUpdate().Text("inc"))))
+
+	data, _, _ := storage.Load("session.counter:user:100")
+	assert.JSONEq(t, `{"n":2}`, string(data))
+	data, _, _ = storage.Load("session.profile:user:100")
+	assert.JSONEq(t, `{"name":"alice"}`, string(data))
+}
+
+func TestMiddlewareScope(t *testing.T) {
+	storage := NewMemoryStorage()
+	rec := &teletest.Recorder{}
+
+	group := &tele.Chat{ID: -100, Type: tele.ChatGroup}
+	u := teletest.NewUpdate().From(&tele.User{ID: 1}).InChat(group).Text("hi")
+
+	for scope, key := range map[Scope]string{
+		ByUser:     "session.counter:user:1",
+		ByChat:     "session.counter:chat:-100",
+		ByUserChat: "session.counter:chat:-100:user:1",
+	} {
+		h := Middleware[counter](Config{Storage: storage, Scope: scope})(func(c tele.Context) error {
+			Get[counter](c).N = 1
+			return nil
+		})
+		require.NoError(t, h(rec.NewContext(u)))
+
+		_, version, _ := storage.Load(key)
+		assert.Equal(t, int64(1), version, key)
+	}
+}
+
+func TestMiddlewareConflict(t *testing.T) {
+	storage := NewMemoryStorage()
+	rec := &teletest.Recorder{}
+	m := Middleware[counter](Config{Storage: storage})
+
+	inc := func(c tele.Context) error {
+		Get[counter](c).N++
+		return nil
+	}
+
+	// the session is saved by the concurrent handler meanwhile
+	h := m(func(c tele.Context) error {
+		other := rec.NewContext(teletest.NewUpdate().Text("inc"))
+		if err := m(inc)(other); err != nil {
+			return err
+		}
+		return inc(c)
+	})
+
+	err := h(rec.NewContext(teletest.NewUpdate().Text("inc")))
+	assert.True(t, errors.Is(err, ErrConflict))
+
+	data, _, _ := storage.Load("session.counter:user:100")
+	assert.JSONEq(t, `{"n":1}`, string(data))
+}
+
+func TestMiddlewareError(t *testing.T) {
+	storage := NewMemoryStorage()
+	rec := &teletest.Recorder{}
+	failed := errors.New("failed")
+
+	h := Middleware[counter](Config{Storage: storage})(func(c tele.Context) error {
+		Get[counter](c).N++
+		return failed
+	})
+
+	err := h(rec.NewContext(teletest.NewUpdate().Text("inc")))
+	assert.Equal(t, failed, err)
+
+	data, _, _ := storage.Load("session.counter:user:100")
+	assert.Nil(t, data)
+}
+
+func TestFileStorage(t *testing.T) {
+	dir := t.TempDir()
+
+	s := NewFileStorage(dir)
+	data, version, err := s.Load("chat:1:user:2")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	assert.Zero(t, version)
+
+	require.NoError(t, s.Save("chat:1:user:2", []byte(`{"n":1}`), 0))
+	assert.Equal(t, ErrConflict, s.Save("chat:1:user:2", []byte(`{"n":2}`), 0))
+
+	s = NewFileStorage(dir)
+	data, version, err = s.Load("chat:1:user:2")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"n":1}`, string(data))
+	assert.Equal(t, int64(1), version)
+
+	require.NoError(t, s.Save("chat:1:user:2", []byte(`{"n":2}`), 1))
+}
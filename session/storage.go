@@ -0,0 +1,141 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/telebot.v4/internal/atomicfile"
+)
+
+// ErrConflict is returned when the session is saved, but it has been
+// changed by another handler since it was loaded.
+var ErrConflict = errors.New("session: changed concurrently")
+
+// Storage persists the sessions, encoded as JSON. The sessions are
+// versioned for the optimistic locking, so the concurrent handlers
+// can't overwrite each other's changes.
+type Storage interface {
+	// Load returns the session stored by the key and its version,
+	// or nil and zero version if there is none.
+	Load(key string) (data []byte, version int64, err error)
+
+	// Save stores the session by the key and increments its version,
+	// if the stored version is still the given one. Otherwise, it
+	// returns ErrConflict.
+	Save(key string, data []byte, version int64) error
+}
+
+type entry struct {
+	Data    json.RawMessage `json:"data"`
+	Version int64           `json:"version"`
+}
+
+// MemoryStorage is an in-memory Storage.
+type MemoryStorage struct {
+	mu       sync.Mutex
+	sessions map[string]entry
+}
+
+// NewMemoryStorage returns an empty in-memory storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{sessions: make(map[string]entry)}
+}
+
+// Load implements Storage.
+func (s *MemoryStorage) Load(key string) ([]byte, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.sessions[key]
+	return e.Data, e.Version, nil
+}
+
+// Save implements Storage.
+func (s *MemoryStorage) Save(key string, data []byte, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sessions[key].Version != version {
+		return ErrConflict
+	}
+
+	cp := make([]byte, len(data))
+	copy(cp, data)
+	s.sessions[key] = entry{Data: cp, Version: version + 1}
+	return nil
+}
+
+// FileStorage is a Storage keeping every session
+// in a separate JSON file in the directory.
+type FileStorage struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewFileStorage returns a Storage saving the sessions to the directory,
+// which is created if it doesn't exist.
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{Dir: dir}
+}
+
+// Load implements Storage.
+func (s *FileStorage) Load(key string) ([]byte, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.load(key)
+	return e.Data, e.Version, err
+}
+
+// Save implements Storage.
+func (s *FileStorage) Save(key string, data []byte, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.load(key)
+	if err != nil {
+		return err
+	}
+	if e.Version != version {
+		return ErrConflict
+	}
+
+	data, err = json.Marshal(entry{Data: data, Version: version + 1})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(s.path(key), data)
+}
+
+func (s *FileStorage) load(key string) (entry, error) {
+	var e entry
+
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return e, nil
+	}
+	if err != nil {
+		return e, err
+	}
+
+	err = json.Unmarshal(data, &e)
+	return e, err
+}
+
+// path returns the path to the file of the session,
+// the key is made safe to be used as a file name.
+func (s *FileStorage) path(key string) string {
+	name := strings.NewReplacer(":", "_", "/", "_", `\`, "_").Replace(key)
+	return filepath.Join(s.Dir, name+".json")
+}
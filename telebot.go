@@ -34,6 +34,10 @@ var (
 	ErrCouldNotUpdate  = errors.New("telebot: could not fetch new updates")
 	ErrTrueResult      = errors.New("telebot: result is True")
 	ErrBadContext      = errors.New("telebot: context does not contain message")
+
+	// ErrSkip is returned by the handler or its middleware to pass
+	// the update to the handler set for the endpoint before.
+	ErrSkip = errors.New("telebot: handler skipped")
 )
 
 const DefaultApiURL = "https://api.telegram.org"
@@ -0,0 +1,414 @@
+package teletest
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Record is an API method call recorded by the Recorder.
+type Record struct {
+	// Method is the name of the API method, e.g. Send.
+	Method string
+
+	// To is the recipient of the sent message.
+	To tele.Recipient
+
+	// Msg is the edited, deleted or replied message.
+	Msg tele.Editable
+
+	// What is the sent content: the text, the caption
+	// or the media.
+	What interface{}
+
+	// Opts are the options the method is called with,
+	// and Options are the send options they make up.
+	Opts    []interface{}
+	Options *tele.SendOptions
+
+	// Args are all the arguments of the call.
+	Args []interface{}
+
+	// Err is the scripted error returned from the call.
+	Err error
+}
+
+// Text returns the text or the caption of the sent content.
+func (rec Record) Text() string {
+	switch what := rec.What.(type) {
+	case string:
+		return what
+	case tele.Inputtable:
+		return what.InputMedia().Caption
+	default:
+		return ""
+	}
+}
+
+// Markup returns the reply markup the method is called with.
+func (rec Record) Markup() *tele.ReplyMarkup {
+	if rec.Options == nil {
+		return nil
+	}
+	return rec.Options.ReplyMarkup
+}
+
+// Buttons returns the texts of the markup buttons, row by row.
+func (rec Record) Buttons() [][]string {
+	markup := rec.Markup()
+	if markup == nil {
+		return nil
+	}
+
+	var rows [][]string
+	for _, row := range markup.InlineKeyboard {
+		var texts []string
+		for _, btn := range row {
+			texts = append(texts, btn.Text)
+		}
+		rows = append(rows, texts)
+	}
+	for _, row := range markup.ReplyKeyboard {
+		var texts []string
+		for _, btn := range row {
+			texts = append(texts, btn.Text)
+		}
+		rows = append(rows, texts)
+	}
+	return rows
+}
+
+// Has tells whether the method is called with the option.
+func (rec Record) Has(opt tele.Option) bool {
+	for _, o := range rec.Opts {
+		if o == opt {
+			return true
+		}
+	}
+
+	opts, markup := rec.Options, rec.Markup()
+	if opts == nil {
+		opts = &tele.SendOptions{}
+	}
+
+	switch opt {
+	case tele.NoPreview:
+		return opts.DisableWebPagePreview
+	case tele.Silent:
+		return opts.DisableNotification
+	case tele.AllowWithoutReply:
+		return opts.AllowWithoutReply
+	case tele.Protected:
+		return opts.Protected
+	case tele.ForceReply:
+		return markup != nil && markup.ForceReply
+	case tele.OneTimeKeyboard:
+		return markup != nil && markup.OneTimeKeyboard
+	case tele.RemoveKeyboard:
+		return markup != nil && markup.RemoveKeyboard
+	default:
+		return false
+	}
+}
+
+// CallbackResponse returns the response of the Respond call.
+func (rec Record) CallbackResponse() *tele.CallbackResponse {
+	if rec.Method != "Respond" || len(rec.Args) < 2 {
+		return nil
+	}
+	if resp, _ := rec.Args[1].([]*tele.CallbackResponse); len(resp) > 0 {
+		return resp[0]
+	}
+	return &tele.CallbackResponse{}
+}
+
+// QueryResponse returns the response of the Answer call.
+func (rec Record) QueryResponse() *tele.QueryResponse {
+	if rec.Method != "Answer" || len(rec.Args) < 2 {
+		return nil
+	}
+	resp, _ := rec.Args[1].(*tele.QueryResponse)
+	return resp
+}
+
+// AssertText checks the text or the caption of the sent content.
+func (rec Record) AssertText(t testing.TB, text string) bool {
+	t.Helper()
+	if got := rec.Text(); got != text {
+		t.Errorf("teletest: %s text: expected %q, got %q", rec.Method, text, got)
+		return false
+	}
+	return true
+}
+
+// AssertButtons checks the texts of the markup buttons, all the
+// buttons are listed row by row.
+func (rec Record) AssertButtons(t testing.TB, texts ...string) bool {
+	t.Helper()
+
+	var got []string
+	for _, row := range rec.Buttons() {
+		got = append(got, row...)
+	}
+
+	if len(got) != len(texts) {
+		t.Errorf("teletest: %s buttons: expected %q, got %q", rec.Method, texts, got)
+		return false
+	}
+	for i := range got {
+		if got[i] != texts[i] {
+			t.Errorf("teletest: %s buttons: expected %q, got %q", rec.Method, texts, got)
+			return false
+		}
+	}
+	return true
+}
+
+// AssertOption checks the method is called with the option.
+func (rec Record) AssertOption(t testing.TB, opt tele.Option) bool {
+	t.Helper()
+	if !rec.Has(opt) {
+		t.Errorf("teletest: %s is called without option %d", rec.Method, opt)
+		return false
+	}
+	return true
+}
+
+// Recorder is a fake API recording every call made through it, so the
+// handlers can be tested without a bot and network. The methods return
+// the plausible messages, or zero values for the other results.
+//
+// Example:
+//
+//		rec := &teletest.Recorder{}
+//		c := rec.NewContext(update)
+//
+//		err := onStart(c)
+//		require.NoError(t, err)
+//
+//		last, _ := rec.Last("Send", "Reply")
+//		last.AssertText(t, "Welcome!")
+//		last.AssertButtons(t, "Help", "Settings")
+//
+type Recorder struct {
+	mu        sync.Mutex
+	records   []Record
+	errs      map[string][]error
+	messageID int
+}
+
+// NewContext returns the context of the update using the recorder.
+func (r *Recorder) NewContext(u tele.Update) tele.Context {
+	return tele.NewContext(r, u)
+}
+
+// Calls returns the recorded calls of the given methods,
+// or all of them if no method is given.
+func (r *Recorder) Calls(methods ...string) []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []Record
+	for _, rec := range r.records {
+		if len(methods) == 0 || contains(methods, rec.Method) {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// Last returns the last recorded call of the given methods,
+// or of any method if no method is given.
+func (r *Recorder) Last(methods ...string) (Record, bool) {
+	records := r.Calls(methods...)
+	if len(records) == 0 {
+		return Record{}, false
+	}
+	return records[len(records)-1], true
+}
+
+// Reset forgets the recorded calls and the scripted errors.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = nil
+	r.errs = nil
+}
+
+// Fail makes the next calls of the method fail with the errors,
+// one error per call.
+func (r *Recorder) Fail(method string, errs ...error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.errs == nil {
+		r.errs = make(map[string][]error)
+	}
+	r.errs[method] = append(r.errs[method], errs...)
+}
+
+func (r *Recorder) record(rec Record) Record {
+	rec.Options = options(rec.Opts)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if errs := r.errs[rec.Method]; len(errs) > 0 {
+		rec.Err = errs[0]
+		r.errs[rec.Method] = errs[1:]
+	}
+	r.records = append(r.records, rec)
+	return rec
+}
+
+// message returns the message sent or edited with the call.
+func (r *Recorder) message(rec Record) (*tele.Message, error) {
+	if rec.Err != nil {
+		return nil, rec.Err
+	}
+
+	msg := &tele.Message{
+		Unixtime:    time.Now().Unix(),
+		ReplyMarkup: rec.Markup(),
+	}
+
+	if rec.Msg != nil {
+		id, chatID := rec.Msg.MessageSig()
+		msg.ID, _ = strconv.Atoi(id)
+		msg.Chat = &tele.Chat{ID: chatID}
+	}
+	if rec.To != nil {
+		msg.Chat = recipientChat(rec.To)
+	}
+	if rec.Method != "Send" && rec.Method != "Reply" &&
+		rec.Method != "Forward" && rec.Method != "Copy" && rec.Method != "SendPaid" {
+		return r.content(msg, rec), nil
+	}
+
+	r.mu.Lock()
+	r.messageID++
+	msg.ID = r.messageID
+	r.mu.Unlock()
+
+	if rec.Method == "Reply" {
+		msg.ReplyTo, _ = rec.Msg.(*tele.Message)
+	}
+	return r.content(msg, rec), nil
+}
+
+// album returns the messages of the album sent with the call.
+func (r *Recorder) album(rec Record) ([]tele.Message, error) {
+	if rec.Err != nil {
+		return nil, rec.Err
+	}
+
+	a, _ := rec.What.(tele.Album)
+	msgs := make([]tele.Message, len(a))
+	for i, x := range a {
+		m, _ := r.message(Record{Method: "Send", To: rec.To, What: x, Options: rec.Options})
+		msgs[i] = *m
+	}
+	return msgs, nil
+}
+
+// content sets the text or the media of the message.
+func (r *Recorder) content(msg *tele.Message, rec Record) *tele.Message {
+	switch what := rec.What.(type) {
+	case string:
+		if rec.Method == "EditCaption" {
+			msg.Caption = what
+		} else {
+			msg.Text = what
+		}
+	case tele.Inputtable:
+		msg.Caption = what.InputMedia().Caption
+		switch m := what.(type) {
+		case *tele.Photo:
+			msg.Photo = m
+		case *tele.Video:
+			msg.Video = m
+		case *tele.Audio:
+			msg.Audio = m
+		case *tele.Document:
+			msg.Document = m
+		case *tele.Animation:
+			msg.Animation = m
+		}
+	case *tele.Location:
+		msg.Location = what
+	case *tele.Poll:
+		msg.Poll = what
+	case *tele.Dice:
+		msg.Dice = what
+	case *tele.Sticker:
+		msg.Sticker = what
+	case *tele.Voice:
+		msg.Voice = what
+	case *tele.VideoNote:
+		msg.VideoNote = what
+	}
+	return msg
+}
+
+func recipientChat(to tele.Recipient) *tele.Chat {
+	switch to := to.(type) {
+	case *tele.Chat:
+		return to
+	case *tele.User:
+		return &tele.Chat{ID: to.ID, Type: tele.ChatPrivate, Username: to.Username}
+	default:
+		return chat(to.Recipient())
+	}
+}
+
+// options makes up the send options the way the bot does.
+func options(opts []interface{}) *tele.SendOptions {
+	sendOpts := &tele.SendOptions{}
+	markup := func() *tele.ReplyMarkup {
+		if sendOpts.ReplyMarkup == nil {
+			sendOpts.ReplyMarkup = &tele.ReplyMarkup{}
+		}
+		return sendOpts.ReplyMarkup
+	}
+
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case *tele.SendOptions:
+			cp := *opt
+			sendOpts = &cp
+		case *tele.ReplyMarkup:
+			sendOpts.ReplyMarkup = opt
+		case *tele.ReplyParams:
+			sendOpts.ReplyParams = opt
+		case *tele.Topic:
+			sendOpts.ThreadID = opt.ThreadID
+		case tele.ParseMode:
+			sendOpts.ParseMode = opt
+		case tele.Entities:
+			sendOpts.Entities = opt
+		case tele.Option:
+			switch opt {
+			case tele.NoPreview:
+				sendOpts.DisableWebPagePreview = true
+			case tele.Silent:
+				sendOpts.DisableNotification = true
+			case tele.AllowWithoutReply:
+				sendOpts.AllowWithoutReply = true
+			case tele.Protected:
+				sendOpts.Protected = true
+			case tele.ForceReply:
+				markup().ForceReply = true
+			case tele.OneTimeKeyboard:
+				markup().OneTimeKeyboard = true
+			case tele.RemoveKeyboard:
+				markup().RemoveKeyboard = true
+			}
+		}
+	}
+
+	return sendOpts
+}
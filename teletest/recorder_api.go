@@ -0,0 +1,1036 @@
+package teletest
+
+import (
+	"io"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Raw implements tele.API.
+func (r *Recorder) Raw(method string, payload interface{}) ([]byte, error) {
+	rec := r.record(Record{
+		Method: "Raw",
+		Args:   []interface{}{method, payload},
+	})
+	return nil, rec.Err
+}
+
+// Accept implements tele.API.
+func (r *Recorder) Accept(query *tele.PreCheckoutQuery, errorMessage ...string) error {
+	rec := r.record(Record{
+		Method: "Accept",
+		Args:   []interface{}{query, errorMessage},
+	})
+	return rec.Err
+}
+
+// AddStickerToSet implements tele.API.
+func (r *Recorder) AddStickerToSet(of tele.Recipient, name string, sticker tele.InputSticker) error {
+	rec := r.record(Record{
+		Method: "AddStickerToSet",
+		Args:   []interface{}{of, name, sticker},
+	})
+	return rec.Err
+}
+
+// AdminsOf implements tele.API.
+func (r *Recorder) AdminsOf(chat *tele.Chat) ([]tele.ChatMember, error) {
+	rec := r.record(Record{
+		Method: "AdminsOf",
+		Args:   []interface{}{chat},
+	})
+	return nil, rec.Err
+}
+
+// Answer implements tele.API.
+func (r *Recorder) Answer(query *tele.Query, resp *tele.QueryResponse) error {
+	rec := r.record(Record{
+		Method: "Answer",
+		Args:   []interface{}{query, resp},
+	})
+	return rec.Err
+}
+
+// AnswerWebApp implements tele.API.
+func (r *Recorder) AnswerWebApp(query *tele.Query, result tele.Result) (*tele.WebAppMessage, error) {
+	rec := r.record(Record{
+		Method: "AnswerWebApp",
+		Args:   []interface{}{query, result},
+	})
+	return nil, rec.Err
+}
+
+// ApproveJoinRequest implements tele.API.
+func (r *Recorder) ApproveJoinRequest(chat tele.Recipient, user *tele.User) error {
+	rec := r.record(Record{
+		Method: "ApproveJoinRequest",
+		Args:   []interface{}{chat, user},
+	})
+	return rec.Err
+}
+
+// Ban implements tele.API.
+func (r *Recorder) Ban(chat *tele.Chat, member *tele.ChatMember, revokeMessages ...bool) error {
+	rec := r.record(Record{
+		Method: "Ban",
+		Args:   []interface{}{chat, member, revokeMessages},
+	})
+	return rec.Err
+}
+
+// BanSenderChat implements tele.API.
+func (r *Recorder) BanSenderChat(chat *tele.Chat, sender tele.Recipient) error {
+	rec := r.record(Record{
+		Method: "BanSenderChat",
+		Args:   []interface{}{chat, sender},
+	})
+	return rec.Err
+}
+
+// BusinessConnection implements tele.API.
+func (r *Recorder) BusinessConnection(id string) (*tele.BusinessConnection, error) {
+	rec := r.record(Record{
+		Method: "BusinessConnection",
+		Args:   []interface{}{id},
+	})
+	return nil, rec.Err
+}
+
+// ChatByID implements tele.API.
+func (r *Recorder) ChatByID(id int64) (*tele.Chat, error) {
+	rec := r.record(Record{
+		Method: "ChatByID",
+		Args:   []interface{}{id},
+	})
+	return nil, rec.Err
+}
+
+// ChatByUsername implements tele.API.
+func (r *Recorder) ChatByUsername(name string) (*tele.Chat, error) {
+	rec := r.record(Record{
+		Method: "ChatByUsername",
+		Args:   []interface{}{name},
+	})
+	return nil, rec.Err
+}
+
+// ChatMemberOf implements tele.API.
+func (r *Recorder) ChatMemberOf(chat tele.Recipient, user tele.Recipient) (*tele.ChatMember, error) {
+	rec := r.record(Record{
+		Method: "ChatMemberOf",
+		Args:   []interface{}{chat, user},
+	})
+	return nil, rec.Err
+}
+
+// Close implements tele.API.
+func (r *Recorder) Close() (bool, error) {
+	rec := r.record(Record{
+		Method: "Close",
+		Args:   []interface{}{},
+	})
+	return false, rec.Err
+}
+
+// CloseGeneralTopic implements tele.API.
+func (r *Recorder) CloseGeneralTopic(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "CloseGeneralTopic",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// CloseTopic implements tele.API.
+func (r *Recorder) CloseTopic(chat *tele.Chat, topic *tele.Topic) error {
+	rec := r.record(Record{
+		Method: "CloseTopic",
+		Args:   []interface{}{chat, topic},
+	})
+	return rec.Err
+}
+
+// Commands implements tele.API.
+func (r *Recorder) Commands(opts ...interface{}) ([]tele.Command, error) {
+	rec := r.record(Record{
+		Method: "Commands",
+		Opts:   opts,
+		Args:   []interface{}{opts},
+	})
+	return nil, rec.Err
+}
+
+// Copy implements tele.API.
+func (r *Recorder) Copy(to tele.Recipient, msg tele.Editable, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "Copy",
+		To:     to,
+		Msg:    msg,
+		Opts:   opts,
+		Args:   []interface{}{to, msg, opts},
+	})
+	return r.message(rec)
+}
+
+// CopyMany implements tele.API.
+func (r *Recorder) CopyMany(to tele.Recipient, msgs []tele.Editable, opts ...*tele.SendOptions) ([]tele.Message, error) {
+	rec := r.record(Record{
+		Method: "CopyMany",
+		To:     to,
+		Args:   []interface{}{to, msgs, opts},
+	})
+	return nil, rec.Err
+}
+
+// CreateInviteLink implements tele.API.
+func (r *Recorder) CreateInviteLink(chat tele.Recipient, link *tele.ChatInviteLink) (*tele.ChatInviteLink, error) {
+	rec := r.record(Record{
+		Method: "CreateInviteLink",
+		Args:   []interface{}{chat, link},
+	})
+	return nil, rec.Err
+}
+
+// CreateInvoiceLink implements tele.API.
+func (r *Recorder) CreateInvoiceLink(i tele.Invoice) (string, error) {
+	rec := r.record(Record{
+		Method: "CreateInvoiceLink",
+		Args:   []interface{}{i},
+	})
+	return "", rec.Err
+}
+
+// CreateStickerSet implements tele.API.
+func (r *Recorder) CreateStickerSet(of tele.Recipient, set *tele.StickerSet) error {
+	rec := r.record(Record{
+		Method: "CreateStickerSet",
+		Args:   []interface{}{of, set},
+	})
+	return rec.Err
+}
+
+// CreateTopic implements tele.API.
+func (r *Recorder) CreateTopic(chat *tele.Chat, topic *tele.Topic) (*tele.Topic, error) {
+	rec := r.record(Record{
+		Method: "CreateTopic",
+		Args:   []interface{}{chat, topic},
+	})
+	return nil, rec.Err
+}
+
+// CustomEmojiStickers implements tele.API.
+func (r *Recorder) CustomEmojiStickers(ids []string) ([]tele.Sticker, error) {
+	rec := r.record(Record{
+		Method: "CustomEmojiStickers",
+		Args:   []interface{}{ids},
+	})
+	return nil, rec.Err
+}
+
+// DeclineJoinRequest implements tele.API.
+func (r *Recorder) DeclineJoinRequest(chat tele.Recipient, user *tele.User) error {
+	rec := r.record(Record{
+		Method: "DeclineJoinRequest",
+		Args:   []interface{}{chat, user},
+	})
+	return rec.Err
+}
+
+// DefaultRights implements tele.API.
+func (r *Recorder) DefaultRights(forChannels bool) (*tele.Rights, error) {
+	rec := r.record(Record{
+		Method: "DefaultRights",
+		Args:   []interface{}{forChannels},
+	})
+	return nil, rec.Err
+}
+
+// Delete implements tele.API.
+func (r *Recorder) Delete(msg tele.Editable) error {
+	rec := r.record(Record{
+		Method: "Delete",
+		Msg:    msg,
+		Args:   []interface{}{msg},
+	})
+	return rec.Err
+}
+
+// DeleteCommands implements tele.API.
+func (r *Recorder) DeleteCommands(opts ...interface{}) error {
+	rec := r.record(Record{
+		Method: "DeleteCommands",
+		Opts:   opts,
+		Args:   []interface{}{opts},
+	})
+	return rec.Err
+}
+
+// DeleteGroupPhoto implements tele.API.
+func (r *Recorder) DeleteGroupPhoto(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "DeleteGroupPhoto",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// DeleteGroupStickerSet implements tele.API.
+func (r *Recorder) DeleteGroupStickerSet(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "DeleteGroupStickerSet",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// DeleteMany implements tele.API.
+func (r *Recorder) DeleteMany(msgs []tele.Editable) error {
+	rec := r.record(Record{
+		Method: "DeleteMany",
+		Args:   []interface{}{msgs},
+	})
+	return rec.Err
+}
+
+// DeleteSticker implements tele.API.
+func (r *Recorder) DeleteSticker(sticker string) error {
+	rec := r.record(Record{
+		Method: "DeleteSticker",
+		Args:   []interface{}{sticker},
+	})
+	return rec.Err
+}
+
+// DeleteStickerSet implements tele.API.
+func (r *Recorder) DeleteStickerSet(name string) error {
+	rec := r.record(Record{
+		Method: "DeleteStickerSet",
+		Args:   []interface{}{name},
+	})
+	return rec.Err
+}
+
+// DeleteTopic implements tele.API.
+func (r *Recorder) DeleteTopic(chat *tele.Chat, topic *tele.Topic) error {
+	rec := r.record(Record{
+		Method: "DeleteTopic",
+		Args:   []interface{}{chat, topic},
+	})
+	return rec.Err
+}
+
+// Download implements tele.API.
+func (r *Recorder) Download(file *tele.File, localFilename string) error {
+	rec := r.record(Record{
+		Method: "Download",
+		Args:   []interface{}{file, localFilename},
+	})
+	return rec.Err
+}
+
+// Edit implements tele.API.
+func (r *Recorder) Edit(msg tele.Editable, what interface{}, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "Edit",
+		Msg:    msg,
+		What:   what,
+		Opts:   opts,
+		Args:   []interface{}{msg, what, opts},
+	})
+	return r.message(rec)
+}
+
+// EditCaption implements tele.API.
+func (r *Recorder) EditCaption(msg tele.Editable, caption string, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "EditCaption",
+		Msg:    msg,
+		What:   caption,
+		Opts:   opts,
+		Args:   []interface{}{msg, caption, opts},
+	})
+	return r.message(rec)
+}
+
+// EditGeneralTopic implements tele.API.
+func (r *Recorder) EditGeneralTopic(chat *tele.Chat, topic *tele.Topic) error {
+	rec := r.record(Record{
+		Method: "EditGeneralTopic",
+		Args:   []interface{}{chat, topic},
+	})
+	return rec.Err
+}
+
+// EditInviteLink implements tele.API.
+func (r *Recorder) EditInviteLink(chat tele.Recipient, link *tele.ChatInviteLink) (*tele.ChatInviteLink, error) {
+	rec := r.record(Record{
+		Method: "EditInviteLink",
+		Args:   []interface{}{chat, link},
+	})
+	return nil, rec.Err
+}
+
+// EditMedia implements tele.API.
+func (r *Recorder) EditMedia(msg tele.Editable, media tele.Inputtable, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "EditMedia",
+		Msg:    msg,
+		What:   media,
+		Opts:   opts,
+		Args:   []interface{}{msg, media, opts},
+	})
+	return r.message(rec)
+}
+
+// EditReplyMarkup implements tele.API.
+func (r *Recorder) EditReplyMarkup(msg tele.Editable, markup *tele.ReplyMarkup) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "EditReplyMarkup",
+		Msg:    msg,
+		Opts:   []interface{}{markup},
+		Args:   []interface{}{msg, markup},
+	})
+	return r.message(rec)
+}
+
+// EditTopic implements tele.API.
+func (r *Recorder) EditTopic(chat *tele.Chat, topic *tele.Topic) error {
+	rec := r.record(Record{
+		Method: "EditTopic",
+		Args:   []interface{}{chat, topic},
+	})
+	return rec.Err
+}
+
+// File implements tele.API.
+func (r *Recorder) File(file *tele.File) (io.ReadCloser, error) {
+	rec := r.record(Record{
+		Method: "File",
+		Args:   []interface{}{file},
+	})
+	return nil, rec.Err
+}
+
+// FileByID implements tele.API.
+func (r *Recorder) FileByID(fileID string) (tele.File, error) {
+	rec := r.record(Record{
+		Method: "FileByID",
+		Args:   []interface{}{fileID},
+	})
+	return tele.File{}, rec.Err
+}
+
+// Forward implements tele.API.
+func (r *Recorder) Forward(to tele.Recipient, msg tele.Editable, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "Forward",
+		To:     to,
+		Msg:    msg,
+		Opts:   opts,
+		Args:   []interface{}{to, msg, opts},
+	})
+	return r.message(rec)
+}
+
+// ForwardMany implements tele.API.
+func (r *Recorder) ForwardMany(to tele.Recipient, msgs []tele.Editable, opts ...*tele.SendOptions) ([]tele.Message, error) {
+	rec := r.record(Record{
+		Method: "ForwardMany",
+		To:     to,
+		Args:   []interface{}{to, msgs, opts},
+	})
+	return nil, rec.Err
+}
+
+// GameScores implements tele.API.
+func (r *Recorder) GameScores(user tele.Recipient, msg tele.Editable) ([]tele.GameHighScore, error) {
+	rec := r.record(Record{
+		Method: "GameScores",
+		Msg:    msg,
+		Args:   []interface{}{user, msg},
+	})
+	return nil, rec.Err
+}
+
+// HideGeneralTopic implements tele.API.
+func (r *Recorder) HideGeneralTopic(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "HideGeneralTopic",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// InviteLink implements tele.API.
+func (r *Recorder) InviteLink(chat *tele.Chat) (string, error) {
+	rec := r.record(Record{
+		Method: "InviteLink",
+		Args:   []interface{}{chat},
+	})
+	return "", rec.Err
+}
+
+// Leave implements tele.API.
+func (r *Recorder) Leave(chat tele.Recipient) error {
+	rec := r.record(Record{
+		Method: "Leave",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// Len implements tele.API.
+func (r *Recorder) Len(chat *tele.Chat) (int, error) {
+	rec := r.record(Record{
+		Method: "Len",
+		Args:   []interface{}{chat},
+	})
+	return 0, rec.Err
+}
+
+// Logout implements tele.API.
+func (r *Recorder) Logout() (bool, error) {
+	rec := r.record(Record{
+		Method: "Logout",
+		Args:   []interface{}{},
+	})
+	return false, rec.Err
+}
+
+// MenuButton implements tele.API.
+func (r *Recorder) MenuButton(chat *tele.User) (*tele.MenuButton, error) {
+	rec := r.record(Record{
+		Method: "MenuButton",
+		Args:   []interface{}{chat},
+	})
+	return nil, rec.Err
+}
+
+// MyDescription implements tele.API.
+func (r *Recorder) MyDescription(language string) (*tele.BotInfo, error) {
+	rec := r.record(Record{
+		Method: "MyDescription",
+		Args:   []interface{}{language},
+	})
+	return nil, rec.Err
+}
+
+// MyName implements tele.API.
+func (r *Recorder) MyName(language string) (*tele.BotInfo, error) {
+	rec := r.record(Record{
+		Method: "MyName",
+		Args:   []interface{}{language},
+	})
+	return nil, rec.Err
+}
+
+// MyShortDescription implements tele.API.
+func (r *Recorder) MyShortDescription(language string) (*tele.BotInfo, error) {
+	rec := r.record(Record{
+		Method: "MyShortDescription",
+		Args:   []interface{}{language},
+	})
+	return nil, rec.Err
+}
+
+// Notify implements tele.API.
+func (r *Recorder) Notify(to tele.Recipient, action tele.ChatAction, threadID ...int) error {
+	rec := r.record(Record{
+		Method: "Notify",
+		To:     to,
+		Args:   []interface{}{to, action, threadID},
+	})
+	return rec.Err
+}
+
+// Pin implements tele.API.
+func (r *Recorder) Pin(msg tele.Editable, opts ...interface{}) error {
+	rec := r.record(Record{
+		Method: "Pin",
+		Msg:    msg,
+		Opts:   opts,
+		Args:   []interface{}{msg, opts},
+	})
+	return rec.Err
+}
+
+// ProfilePhotosOf implements tele.API.
+func (r *Recorder) ProfilePhotosOf(user *tele.User) ([]tele.Photo, error) {
+	rec := r.record(Record{
+		Method: "ProfilePhotosOf",
+		Args:   []interface{}{user},
+	})
+	return nil, rec.Err
+}
+
+// Promote implements tele.API.
+func (r *Recorder) Promote(chat *tele.Chat, member *tele.ChatMember) error {
+	rec := r.record(Record{
+		Method: "Promote",
+		Args:   []interface{}{chat, member},
+	})
+	return rec.Err
+}
+
+// React implements tele.API.
+func (r *Recorder) React(to tele.Recipient, msg tele.Editable, result tele.Reactions) error {
+	rec := r.record(Record{
+		Method: "React",
+		To:     to,
+		Msg:    msg,
+		Args:   []interface{}{to, msg, result},
+	})
+	return rec.Err
+}
+
+// RefundStars implements tele.API.
+func (r *Recorder) RefundStars(to tele.Recipient, chargeID string) error {
+	rec := r.record(Record{
+		Method: "RefundStars",
+		To:     to,
+		Args:   []interface{}{to, chargeID},
+	})
+	return rec.Err
+}
+
+// RemoveWebhook implements tele.API.
+func (r *Recorder) RemoveWebhook(dropPending ...bool) error {
+	rec := r.record(Record{
+		Method: "RemoveWebhook",
+		Args:   []interface{}{dropPending},
+	})
+	return rec.Err
+}
+
+// ReopenGeneralTopic implements tele.API.
+func (r *Recorder) ReopenGeneralTopic(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "ReopenGeneralTopic",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// ReopenTopic implements tele.API.
+func (r *Recorder) ReopenTopic(chat *tele.Chat, topic *tele.Topic) error {
+	rec := r.record(Record{
+		Method: "ReopenTopic",
+		Args:   []interface{}{chat, topic},
+	})
+	return rec.Err
+}
+
+// ReplaceStickerInSet implements tele.API.
+func (r *Recorder) ReplaceStickerInSet(of tele.Recipient, stickerSet string, oldSticker string, sticker tele.InputSticker) (bool, error) {
+	rec := r.record(Record{
+		Method: "ReplaceStickerInSet",
+		Args:   []interface{}{of, stickerSet, oldSticker, sticker},
+	})
+	return false, rec.Err
+}
+
+// Reply implements tele.API.
+func (r *Recorder) Reply(to *tele.Message, what interface{}, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "Reply",
+		Msg:    to,
+		What:   what,
+		Opts:   opts,
+		Args:   []interface{}{to, what, opts},
+	})
+	return r.message(rec)
+}
+
+// Respond implements tele.API.
+func (r *Recorder) Respond(c *tele.Callback, resp ...*tele.CallbackResponse) error {
+	rec := r.record(Record{
+		Method: "Respond",
+		Args:   []interface{}{c, resp},
+	})
+	return rec.Err
+}
+
+// Restrict implements tele.API.
+func (r *Recorder) Restrict(chat *tele.Chat, member *tele.ChatMember) error {
+	rec := r.record(Record{
+		Method: "Restrict",
+		Args:   []interface{}{chat, member},
+	})
+	return rec.Err
+}
+
+// RevokeInviteLink implements tele.API.
+func (r *Recorder) RevokeInviteLink(chat tele.Recipient, link string) (*tele.ChatInviteLink, error) {
+	rec := r.record(Record{
+		Method: "RevokeInviteLink",
+		Args:   []interface{}{chat, link},
+	})
+	return nil, rec.Err
+}
+
+// Send implements tele.API.
+func (r *Recorder) Send(to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "Send",
+		To:     to,
+		What:   what,
+		Opts:   opts,
+		Args:   []interface{}{to, what, opts},
+	})
+	return r.message(rec)
+}
+
+// SendAlbum implements tele.API.
+func (r *Recorder) SendAlbum(to tele.Recipient, a tele.Album, opts ...interface{}) ([]tele.Message, error) {
+	rec := r.record(Record{
+		Method: "SendAlbum",
+		To:     to,
+		What:   a,
+		Opts:   opts,
+		Args:   []interface{}{to, a, opts},
+	})
+	return r.album(rec)
+}
+
+// SendPaid implements tele.API.
+func (r *Recorder) SendPaid(to tele.Recipient, stars int, a tele.PaidAlbum, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "SendPaid",
+		To:     to,
+		What:   a,
+		Opts:   opts,
+		Args:   []interface{}{to, stars, a, opts},
+	})
+	return r.message(rec)
+}
+
+// SetAdminTitle implements tele.API.
+func (r *Recorder) SetAdminTitle(chat *tele.Chat, user *tele.User, title string) error {
+	rec := r.record(Record{
+		Method: "SetAdminTitle",
+		Args:   []interface{}{chat, user, title},
+	})
+	return rec.Err
+}
+
+// SetCommands implements tele.API.
+func (r *Recorder) SetCommands(opts ...interface{}) error {
+	rec := r.record(Record{
+		Method: "SetCommands",
+		Opts:   opts,
+		Args:   []interface{}{opts},
+	})
+	return rec.Err
+}
+
+// SetCustomEmojiStickerSetThumb implements tele.API.
+func (r *Recorder) SetCustomEmojiStickerSetThumb(name string, id string) error {
+	rec := r.record(Record{
+		Method: "SetCustomEmojiStickerSetThumb",
+		Args:   []interface{}{name, id},
+	})
+	return rec.Err
+}
+
+// SetDefaultRights implements tele.API.
+func (r *Recorder) SetDefaultRights(rights tele.Rights, forChannels bool) error {
+	rec := r.record(Record{
+		Method: "SetDefaultRights",
+		Args:   []interface{}{rights, forChannels},
+	})
+	return rec.Err
+}
+
+// SetGameScore implements tele.API.
+func (r *Recorder) SetGameScore(user tele.Recipient, msg tele.Editable, score tele.GameHighScore) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "SetGameScore",
+		Msg:    msg,
+		Args:   []interface{}{user, msg, score},
+	})
+	return r.message(rec)
+}
+
+// SetGroupDescription implements tele.API.
+func (r *Recorder) SetGroupDescription(chat *tele.Chat, description string) error {
+	rec := r.record(Record{
+		Method: "SetGroupDescription",
+		Args:   []interface{}{chat, description},
+	})
+	return rec.Err
+}
+
+// SetGroupPermissions implements tele.API.
+func (r *Recorder) SetGroupPermissions(chat *tele.Chat, perms tele.Rights) error {
+	rec := r.record(Record{
+		Method: "SetGroupPermissions",
+		Args:   []interface{}{chat, perms},
+	})
+	return rec.Err
+}
+
+// SetGroupStickerSet implements tele.API.
+func (r *Recorder) SetGroupStickerSet(chat *tele.Chat, setName string) error {
+	rec := r.record(Record{
+		Method: "SetGroupStickerSet",
+		Args:   []interface{}{chat, setName},
+	})
+	return rec.Err
+}
+
+// SetGroupTitle implements tele.API.
+func (r *Recorder) SetGroupTitle(chat *tele.Chat, title string) error {
+	rec := r.record(Record{
+		Method: "SetGroupTitle",
+		Args:   []interface{}{chat, title},
+	})
+	return rec.Err
+}
+
+// SetMenuButton implements tele.API.
+func (r *Recorder) SetMenuButton(chat *tele.User, mb interface{}) error {
+	rec := r.record(Record{
+		Method: "SetMenuButton",
+		Args:   []interface{}{chat, mb},
+	})
+	return rec.Err
+}
+
+// SetMyDescription implements tele.API.
+func (r *Recorder) SetMyDescription(desc string, language string) error {
+	rec := r.record(Record{
+		Method: "SetMyDescription",
+		Args:   []interface{}{desc, language},
+	})
+	return rec.Err
+}
+
+// SetMyName implements tele.API.
+func (r *Recorder) SetMyName(name string, language string) error {
+	rec := r.record(Record{
+		Method: "SetMyName",
+		Args:   []interface{}{name, language},
+	})
+	return rec.Err
+}
+
+// SetMyShortDescription implements tele.API.
+func (r *Recorder) SetMyShortDescription(desc string, language string) error {
+	rec := r.record(Record{
+		Method: "SetMyShortDescription",
+		Args:   []interface{}{desc, language},
+	})
+	return rec.Err
+}
+
+// SetStickerEmojis implements tele.API.
+func (r *Recorder) SetStickerEmojis(sticker string, emojis []string) error {
+	rec := r.record(Record{
+		Method: "SetStickerEmojis",
+		Args:   []interface{}{sticker, emojis},
+	})
+	return rec.Err
+}
+
+// SetStickerKeywords implements tele.API.
+func (r *Recorder) SetStickerKeywords(sticker string, keywords []string) error {
+	rec := r.record(Record{
+		Method: "SetStickerKeywords",
+		Args:   []interface{}{sticker, keywords},
+	})
+	return rec.Err
+}
+
+// SetStickerMaskPosition implements tele.API.
+func (r *Recorder) SetStickerMaskPosition(sticker string, mask tele.MaskPosition) error {
+	rec := r.record(Record{
+		Method: "SetStickerMaskPosition",
+		Args:   []interface{}{sticker, mask},
+	})
+	return rec.Err
+}
+
+// SetStickerPosition implements tele.API.
+func (r *Recorder) SetStickerPosition(sticker string, position int) error {
+	rec := r.record(Record{
+		Method: "SetStickerPosition",
+		Args:   []interface{}{sticker, position},
+	})
+	return rec.Err
+}
+
+// SetStickerSetThumb implements tele.API.
+func (r *Recorder) SetStickerSetThumb(of tele.Recipient, set *tele.StickerSet) error {
+	rec := r.record(Record{
+		Method: "SetStickerSetThumb",
+		Args:   []interface{}{of, set},
+	})
+	return rec.Err
+}
+
+// SetStickerSetTitle implements tele.API.
+func (r *Recorder) SetStickerSetTitle(s tele.StickerSet) error {
+	rec := r.record(Record{
+		Method: "SetStickerSetTitle",
+		Args:   []interface{}{s},
+	})
+	return rec.Err
+}
+
+// SetWebhook implements tele.API.
+func (r *Recorder) SetWebhook(w *tele.Webhook) error {
+	rec := r.record(Record{
+		Method: "SetWebhook",
+		Args:   []interface{}{w},
+	})
+	return rec.Err
+}
+
+// Ship implements tele.API.
+func (r *Recorder) Ship(query *tele.ShippingQuery, what ...interface{}) error {
+	rec := r.record(Record{
+		Method: "Ship",
+		Args:   []interface{}{query, what},
+	})
+	return rec.Err
+}
+
+// StarTransactions implements tele.API.
+func (r *Recorder) StarTransactions(offset int, limit int) ([]tele.StarTransaction, error) {
+	rec := r.record(Record{
+		Method: "StarTransactions",
+		Args:   []interface{}{offset, limit},
+	})
+	return nil, rec.Err
+}
+
+// StickerSet implements tele.API.
+func (r *Recorder) StickerSet(name string) (*tele.StickerSet, error) {
+	rec := r.record(Record{
+		Method: "StickerSet",
+		Args:   []interface{}{name},
+	})
+	return nil, rec.Err
+}
+
+// StopLiveLocation implements tele.API.
+func (r *Recorder) StopLiveLocation(msg tele.Editable, opts ...interface{}) (*tele.Message, error) {
+	rec := r.record(Record{
+		Method: "StopLiveLocation",
+		Msg:    msg,
+		Opts:   opts,
+		Args:   []interface{}{msg, opts},
+	})
+	return r.message(rec)
+}
+
+// StopPoll implements tele.API.
+func (r *Recorder) StopPoll(msg tele.Editable, opts ...interface{}) (*tele.Poll, error) {
+	rec := r.record(Record{
+		Method: "StopPoll",
+		Msg:    msg,
+		Opts:   opts,
+		Args:   []interface{}{msg, opts},
+	})
+	return nil, rec.Err
+}
+
+// TopicIconStickers implements tele.API.
+func (r *Recorder) TopicIconStickers() ([]tele.Sticker, error) {
+	rec := r.record(Record{
+		Method: "TopicIconStickers",
+		Args:   []interface{}{},
+	})
+	return nil, rec.Err
+}
+
+// Unban implements tele.API.
+func (r *Recorder) Unban(chat *tele.Chat, user *tele.User, forBanned ...bool) error {
+	rec := r.record(Record{
+		Method: "Unban",
+		Args:   []interface{}{chat, user, forBanned},
+	})
+	return rec.Err
+}
+
+// UnbanSenderChat implements tele.API.
+func (r *Recorder) UnbanSenderChat(chat *tele.Chat, sender tele.Recipient) error {
+	rec := r.record(Record{
+		Method: "UnbanSenderChat",
+		Args:   []interface{}{chat, sender},
+	})
+	return rec.Err
+}
+
+// UnhideGeneralTopic implements tele.API.
+func (r *Recorder) UnhideGeneralTopic(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "UnhideGeneralTopic",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// Unpin implements tele.API.
+func (r *Recorder) Unpin(chat tele.Recipient, messageID ...int) error {
+	rec := r.record(Record{
+		Method: "Unpin",
+		Args:   []interface{}{chat, messageID},
+	})
+	return rec.Err
+}
+
+// UnpinAll implements tele.API.
+func (r *Recorder) UnpinAll(chat tele.Recipient) error {
+	rec := r.record(Record{
+		Method: "UnpinAll",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// UnpinAllGeneralTopicMessages implements tele.API.
+func (r *Recorder) UnpinAllGeneralTopicMessages(chat *tele.Chat) error {
+	rec := r.record(Record{
+		Method: "UnpinAllGeneralTopicMessages",
+		Args:   []interface{}{chat},
+	})
+	return rec.Err
+}
+
+// UnpinAllTopicMessages implements tele.API.
+func (r *Recorder) UnpinAllTopicMessages(chat *tele.Chat, topic *tele.Topic) error {
+	rec := r.record(Record{
+		Method: "UnpinAllTopicMessages",
+		Args:   []interface{}{chat, topic},
+	})
+	return rec.Err
+}
+
+// UploadSticker implements tele.API.
+func (r *Recorder) UploadSticker(to tele.Recipient, format tele.StickerSetFormat, f tele.File) (*tele.File, error) {
+	rec := r.record(Record{
+		Method: "UploadSticker",
+		To:     to,
+		Args:   []interface{}{to, format, f},
+	})
+	return nil, rec.Err
+}
+
+// UserBoosts implements tele.API.
+func (r *Recorder) UserBoosts(chat tele.Recipient, user tele.Recipient) ([]tele.Boost, error) {
+	rec := r.record(Record{
+		Method: "UserBoosts",
+		Args:   []interface{}{chat, user},
+	})
+	return nil, rec.Err
+}
+
+// Webhook implements tele.API.
+func (r *Recorder) Webhook() (*tele.Webhook, error) {
+	rec := r.record(Record{
+		Method: "Webhook",
+		Args:   []interface{}{},
+	})
+	return nil, rec.Err
+}
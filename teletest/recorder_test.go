@@ -0,0 +1,85 @@
+package teletest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := &Recorder{}
+
+	msg := &tele.Message{ID: 10, Chat: &tele.Chat{ID: 42}, Text: "/start"}
+	c := rec.NewContext(tele.Update{Message: msg})
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data("Help", "help"), markup.Data("Settings", "settings")))
+
+	sent, err := c.Bot().Send(c.Recipient(), "Welcome!", markup, tele.Silent)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent.ID)
+	assert.Equal(t, int64(42), sent.Chat.ID)
+
+	require.NoError(t, c.Reply("Reply", tele.NoPreview))
+	require.NoError(t, c.Delete())
+
+	last, ok := rec.Last("Send")
+	require.True(t, ok)
+	assert.True(t, last.AssertText(t, "Welcome!"))
+	assert.True(t, last.AssertButtons(t, "Help", "Settings"))
+	assert.True(t, last.AssertOption(t, tele.Silent))
+	assert.False(t, last.Has(tele.NoPreview))
+
+	last, _ = rec.Last("Reply")
+	last.AssertText(t, "Reply")
+	last.AssertOption(t, tele.NoPreview)
+	assert.Equal(t, msg, last.Msg)
+
+	last, _ = rec.Last()
+	assert.Equal(t, "Delete", last.Method)
+	assert.Len(t, rec.Calls(), 3)
+
+	// callbacks
+	c = rec.NewContext(tele.Update{Callback: &tele.Callback{ID: "1", Message: msg}})
+	require.NoError(t, c.Edit("Edited"))
+	require.NoError(t, c.RespondAlert("Done"))
+
+	last, _ = rec.Last("Edit")
+	last.AssertText(t, "Edited")
+
+	edited, err := c.Bot().Edit(msg, "Edited")
+	require.NoError(t, err)
+	assert.Equal(t, 10, edited.ID)
+	assert.Equal(t, "Edited", edited.Text)
+
+	last, _ = rec.Last("Respond")
+	assert.Equal(t, &tele.CallbackResponse{Text: "Done", ShowAlert: true}, last.CallbackResponse())
+
+	// scripted errors
+	rec.Fail("Send", tele.ErrBlockedByUser)
+	assert.Equal(t, tele.ErrBlockedByUser, c.Send("text"))
+	assert.NoError(t, c.Send("text"))
+
+	rec.Reset()
+	assert.Empty(t, rec.Calls())
+}
+
+func TestRecordAssertions(t *testing.T) {
+	mock := &testing.T{}
+	rec := Record{Method: "Send", What: "text", Options: &tele.SendOptions{}}
+
+	assert.False(t, rec.AssertText(mock, "other"))
+	assert.False(t, rec.AssertButtons(mock, "button"))
+	assert.False(t, rec.AssertOption(mock, tele.Silent))
+	assert.True(t, mock.Failed())
+
+	// the empty record of Last with no calls
+	rec, _ = (&Recorder{}).Last("Send")
+	assert.Nil(t, rec.Markup())
+	assert.False(t, rec.Has(tele.Silent))
+	assert.False(t, rec.AssertButtons(mock, "button"))
+	assert.False(t, rec.AssertOption(mock, tele.ForceReply))
+}
@@ -0,0 +1,616 @@
+// Package teletest provides the utilities for testing the bots
+// without Telegram: a fake Bot API server, a recording Context
+// and the builders of the incoming updates.
+package teletest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// Call is a Bot API method call recorded by the Server.
+type Call struct {
+	Method string
+
+	// Params are the decoded params of the call. The non-string
+	// values are kept as JSON, e.g. the reply_markup.
+	Params map[string]string
+
+	// Files are the files uploaded with the multipart request.
+	Files map[string]File
+}
+
+// File is a file uploaded to the Server.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// ResultFunc returns the result of the Bot API method call.
+// The result is marshalled into JSON as is.
+type ResultFunc func(c Call) (interface{}, error)
+
+// Server is a fake Bot API server. It records every method call
+// and replies with the plausible results: the messages sent by the
+// bot, the uploaded files by their IDs or just true. The errors can
+// be scripted with Fail, and the incoming updates are fed to the bot
+// with Push, either through getUpdates or the webhook.
+//
+// Example:
+//
+//		srv := teletest.NewServer()
+//		defer srv.Close()
+//
+//		b, _ := srv.NewBot(tele.Settings{})
+//		b.Handle("/start", onStart)
+//		go b.Start()
+//		defer b.Stop()
+//
+//		srv.Fail("sendMessage", tele.ErrBlockedByUser)
+//		srv.Push(teletest.NewUpdate().Command("/start"))
+//
+type Server struct {
+	*httptest.Server
+
+	// Me is the bot user returned by getMe.
+	Me tele.User
+
+	mu       sync.Mutex
+	calls    []Call
+	errs     map[string][]error
+	results  map[string]ResultFunc
+	updates  []tele.Update
+	updateID int
+	pushed   chan struct{}
+	webhook  map[string]string
+
+	messageID int
+	fileID    int
+	files     map[string]File
+}
+
+// NewServer starts a new fake Bot API server.
+// The server should be closed once it's not needed anymore.
+func NewServer() *Server {
+	s := &Server{
+		Me: tele.User{
+			ID:        1,
+			FirstName: "Bot",
+			Username:  "bot",
+			IsBot:     true,
+		},
+		errs:    make(map[string][]error),
+		results: make(map[string]ResultFunc),
+		pushed:  make(chan struct{}),
+		files:   make(map[string]File),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewBot creates a bot talking to the server. The token and the long
+// poller are set by default, if the settings don't have them.
+func (s *Server) NewBot(pref tele.Settings) (*tele.Bot, error) {
+	pref.URL = s.URL
+	if pref.Token == "" {
+		pref.Token = "TOKEN"
+	}
+	if pref.Poller == nil {
+		pref.Poller = &tele.LongPoller{Timeout: time.Second}
+	}
+	return tele.NewBot(pref)
+}
+
+// Calls returns the recorded calls of the given methods,
+// or all of them if no method is given.
+func (s *Server) Calls(methods ...string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, c := range s.calls {
+		if len(methods) == 0 || contains(methods, c.Method) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Last returns the last recorded call of the method, if any.
+func (s *Server) Last(method string) (Call, bool) {
+	calls := s.Calls(method)
+	if len(calls) == 0 {
+		return Call{}, false
+	}
+	return calls[len(calls)-1], true
+}
+
+// Reset forgets the recorded calls and the scripted errors.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+	s.errs = make(map[string][]error)
+}
+
+// Fail makes the next calls of the method fail with the errors,
+// one error per call. It supports the API errors, e.g. ErrBlockedByUser,
+// FloodError and GroupError, any other error is returned as 400.
+func (s *Server) Fail(method string, errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs[method] = append(s.errs[method], errs...)
+}
+
+// On sets the function returning the result of the method
+// instead of the default one.
+func (s *Server) On(method string, f ResultFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[method] = f
+}
+
+// Push feeds the updates to the bot. They are posted to the webhook,
+// if it's set with setWebhook, or returned from getUpdates otherwise.
+// The updates without ID are given the next ones.
+func (s *Server) Push(updates ...tele.Update) error {
+	s.mu.Lock()
+	for i := range updates {
+		if updates[i].ID == 0 {
+			s.updateID++
+			updates[i].ID = s.updateID
+		} else if updates[i].ID > s.updateID {
+			s.updateID = updates[i].ID
+		}
+	}
+	webhook := s.webhook
+	if webhook == nil {
+		s.updates = append(s.updates, updates...)
+		close(s.pushed)
+		s.pushed = make(chan struct{})
+	}
+	s.mu.Unlock()
+
+	if webhook == nil {
+		return nil
+	}
+
+	for _, u := range updates {
+		data, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, webhook["url"], bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token := webhook["secret_token"]; token != "" {
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("teletest: webhook responded with %s", resp.Status)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/file/") {
+		s.serveFile(w, r)
+		return
+	}
+
+	c, err := decodeCall(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, c)
+	if errs := s.errs[c.Method]; len(errs) > 0 {
+		s.errs[c.Method] = errs[1:]
+		s.mu.Unlock()
+		writeError(w, errs[0])
+		return
+	}
+	f := s.results[c.Method]
+	s.mu.Unlock()
+
+	var result interface{}
+	switch {
+	case f != nil:
+		result, err = f(c)
+	case c.Method == "getUpdates":
+		result = s.getUpdates(r, c)
+	default:
+		result = s.result(c)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":     true,
+		"result": result,
+	})
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	s.mu.Lock()
+	f, ok := s.files[path]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, f.Name, time.Time{}, bytes.NewReader(f.Data))
+}
+
+// getUpdates returns the pending updates, it waits for the
+// new ones up to the timeout.
+func (s *Server) getUpdates(r *http.Request, c Call) []tele.Update {
+	offset, _ := strconv.Atoi(c.Params["offset"])
+	limit, _ := strconv.Atoi(c.Params["limit"])
+	timeout, _ := strconv.Atoi(c.Params["timeout"])
+	deadline := time.After(time.Duration(timeout) * time.Second)
+
+	for {
+		s.mu.Lock()
+		pending := s.updates[:0]
+		for _, u := range s.updates {
+			if u.ID >= offset {
+				pending = append(pending, u)
+			}
+		}
+		s.updates = pending
+		pushed := s.pushed
+		s.mu.Unlock()
+
+		if len(pending) > 0 {
+			if limit > 0 && len(pending) > limit {
+				pending = pending[:limit]
+			}
+			return append([]tele.Update(nil), pending...)
+		}
+
+		select {
+		case <-pushed:
+		case <-deadline:
+			return []tele.Update{}
+		case <-r.Context().Done():
+			return []tele.Update{}
+		}
+	}
+}
+
+// result returns the default result of the call.
+func (s *Server) result(c Call) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch c.Method {
+	case "getMe":
+		return s.Me
+	case "setWebhook":
+		s.webhook = c.Params
+		return true
+	case "deleteWebhook":
+		s.webhook = nil
+		return true
+	case "getWebhookInfo":
+		return map[string]interface{}{"url": s.webhook["url"]}
+	case "getFile":
+		f, ok := s.files[c.Params["file_id"]]
+		size := 0
+		if ok {
+			size = len(f.Data)
+		}
+		return tele.File{
+			FileID:   c.Params["file_id"],
+			FileSize: int64(size),
+			FilePath: c.Params["file_id"],
+		}
+	case "copyMessage":
+		s.messageID++
+		return map[string]int{"message_id": s.messageID}
+	case "sendMediaGroup":
+		return s.album(c)
+	}
+
+	switch {
+	case strings.HasPrefix(c.Method, "send"),
+		c.Method == "forwardMessage":
+		if c.Method == "sendChatAction" {
+			return true
+		}
+		s.messageID++
+		return s.message(c, s.messageID)
+	case strings.HasPrefix(c.Method, "edit"):
+		if c.Params["inline_message_id"] != "" {
+			return true
+		}
+		id, _ := strconv.Atoi(c.Params["message_id"])
+		return s.message(c, id)
+	default:
+		return true
+	}
+}
+
+// mediaFields are the fields of the media sent with the methods.
+var mediaFields = map[string]string{
+	"sendPhoto":     "photo",
+	"sendAudio":     "audio",
+	"sendDocument":  "document",
+	"sendVideo":     "video",
+	"sendAnimation": "animation",
+	"sendVoice":     "voice",
+	"sendVideoNote": "video_note",
+	"sendSticker":   "sticker",
+}
+
+// message returns the message sent or edited with the call.
+func (s *Server) message(c Call, id int) *tele.Message {
+	msg := &tele.Message{
+		ID:       id,
+		Sender:   &s.Me,
+		Chat:     chat(c.Params["chat_id"]),
+		Unixtime: time.Now().Unix(),
+		Text:     c.Params["text"],
+		Caption:  c.Params["caption"],
+	}
+	if c.Method == "forwardMessage" {
+		msg.OriginalChat = chat(c.Params["from_chat_id"])
+	}
+
+	if v := c.Params["reply_markup"]; v != "" {
+		json.Unmarshal([]byte(v), &msg.ReplyMarkup)
+	}
+	if v := c.Params["entities"]; v != "" {
+		json.Unmarshal([]byte(v), &msg.Entities)
+	}
+	if v := c.Params["caption_entities"]; v != "" {
+		json.Unmarshal([]byte(v), &msg.CaptionEntities)
+	}
+	if v := c.Params["reply_parameters"]; v != "" {
+		var params tele.ReplyParams
+		if json.Unmarshal([]byte(v), &params) == nil && params.MessageID != 0 {
+			msg.ReplyTo = &tele.Message{ID: params.MessageID, Chat: msg.Chat}
+		}
+	}
+
+	if c.Method == "editMessageMedia" {
+		var im tele.InputMedia
+		json.Unmarshal([]byte(c.Params["media"]), &im)
+		s.setMedia(msg, im.Type, s.fileRef(c, im.Media))
+		msg.Caption = im.Caption
+	}
+	if field, ok := mediaFields[c.Method]; ok {
+		kind := strings.Replace(field, "_note", "Note", 1)
+		s.setMedia(msg, kind, s.fileRef(c, field))
+	}
+
+	switch c.Method {
+	case "sendLocation":
+		lat, _ := strconv.ParseFloat(c.Params["latitude"], 32)
+		lng, _ := strconv.ParseFloat(c.Params["longitude"], 32)
+		msg.Location = &tele.Location{Lat: float32(lat), Lng: float32(lng)}
+	case "sendDice":
+		msg.Dice = &tele.Dice{Type: tele.DiceType(c.Params["emoji"]), Value: 1}
+	case "sendPoll":
+		msg.Poll = &tele.Poll{Question: c.Params["question"]}
+	}
+
+	return msg
+}
+
+// album returns the messages of the album sent with the call.
+func (s *Server) album(c Call) []*tele.Message {
+	var media []tele.InputMedia
+	json.Unmarshal([]byte(c.Params["media"]), &media)
+
+	s.messageID++
+	group := strconv.Itoa(s.messageID)
+
+	msgs := make([]*tele.Message, len(media))
+	for i, im := range media {
+		if i > 0 {
+			s.messageID++
+		}
+		msgs[i] = &tele.Message{
+			ID:       s.messageID,
+			Sender:   &s.Me,
+			Chat:     chat(c.Params["chat_id"]),
+			Unixtime: time.Now().Unix(),
+			Caption:  im.Caption,
+			AlbumID:  group,
+		}
+		s.setMedia(msgs[i], im.Type, s.fileRef(c, im.Media))
+	}
+	return msgs
+}
+
+// fileRef returns the file ID of the file referenced by the value or
+// field of the call. The uploaded files are given the new IDs, so the
+// bot can download them afterwards.
+func (s *Server) fileRef(c Call, ref string) string {
+	name := strings.TrimPrefix(ref, "attach://")
+	if f, ok := c.Files[name]; ok {
+		s.fileID++
+		id := "file" + strconv.Itoa(s.fileID)
+		s.files[id] = f
+		return id
+	}
+
+	if v, ok := c.Params[ref]; ok {
+		ref = v
+	}
+	if strings.Contains(ref, "://") {
+		s.fileID++
+		return "file" + strconv.Itoa(s.fileID)
+	}
+	return ref
+}
+
+func (s *Server) setMedia(msg *tele.Message, kind, id string) {
+	file := tele.File{FileID: id, UniqueID: id}
+	if f, ok := s.files[id]; ok {
+		file.FileSize = int64(len(f.Data))
+	}
+
+	switch kind {
+	case "photo":
+		msg.Photo = &tele.Photo{File: file}
+	case "audio":
+		msg.Audio = &tele.Audio{File: file}
+	case "document":
+		msg.Document = &tele.Document{File: file}
+	case "video":
+		msg.Video = &tele.Video{File: file}
+	case "animation":
+		msg.Animation = &tele.Animation{File: file}
+	case "voice":
+		msg.Voice = &tele.Voice{File: file}
+	case "videoNote":
+		msg.VideoNote = &tele.VideoNote{File: file}
+	case "sticker":
+		msg.Sticker = &tele.Sticker{File: file}
+	}
+}
+
+func chat(id string) *tele.Chat {
+	if strings.HasPrefix(id, "@") {
+		return &tele.Chat{Username: id[1:], Type: tele.ChatChannel}
+	}
+
+	n, _ := strconv.ParseInt(id, 10, 64)
+	if n < 0 {
+		return &tele.Chat{ID: n, Type: tele.ChatSuperGroup}
+	}
+	return &tele.Chat{ID: n, Type: tele.ChatPrivate}
+}
+
+// decodeCall decodes the method call from the request, either
+// JSON, multipart or form encoded.
+func decodeCall(r *http.Request) (Call, error) {
+	c := Call{
+		Method: r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:],
+		Params: make(map[string]string),
+	}
+
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "application/json":
+		var params map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err != io.EOF {
+			return c, err
+		}
+		for k, v := range params {
+			var s string
+			if json.Unmarshal(v, &s) == nil {
+				c.Params[k] = s
+			} else if string(v) != "null" {
+				c.Params[k] = string(v)
+			}
+		}
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return c, err
+		}
+		for k, v := range r.MultipartForm.Value {
+			c.Params[k] = v[0]
+		}
+
+		c.Files = make(map[string]File)
+		for k, v := range r.MultipartForm.File {
+			f, err := v[0].Open()
+			if err != nil {
+				return c, err
+			}
+			data, err := ioutil.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return c, err
+			}
+			c.Files[k] = File{Name: v[0].Filename, Data: data}
+		}
+	default:
+		if err := r.ParseForm(); err != nil {
+			return c, err
+		}
+		for k, v := range r.Form {
+			c.Params[k] = v[0]
+		}
+	}
+
+	return c, nil
+}
+
+// writeError writes the error the way Bot API does.
+func writeError(w http.ResponseWriter, err error) {
+	resp := map[string]interface{}{"ok": false}
+
+	var (
+		flood tele.FloodError
+		group tele.GroupError
+		e     *tele.Error
+	)
+	switch {
+	case errors.As(err, &flood):
+		resp["error_code"] = http.StatusTooManyRequests
+		resp["description"] = "Too Many Requests: retry after " + strconv.Itoa(flood.RetryAfter)
+		resp["parameters"] = map[string]interface{}{"retry_after": flood.RetryAfter}
+	case errors.As(err, &group):
+		resp["error_code"] = tele.ErrGroupMigrated.Code
+		resp["description"] = tele.ErrGroupMigrated.Description
+		resp["parameters"] = map[string]interface{}{"migrate_to_chat_id": group.MigratedTo}
+	case errors.As(err, &e):
+		resp["error_code"] = e.Code
+		resp["description"] = e.Description
+	default:
+		resp["error_code"] = http.StatusBadRequest
+		resp["description"] = "Bad Request: " + err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp["error_code"].(int))
+	json.NewEncoder(w).Encode(resp)
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package teletest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	b, err := srv.NewBot(tele.Settings{})
+	require.NoError(t, err)
+	assert.Equal(t, "bot", b.Me.Username)
+
+	chat := &tele.Chat{ID: 42}
+	markup := b.NewMarkup()
+	markup.Inline(markup.Row(markup.Data("Yes", "yes")))
+
+	msg, err := b.Send(chat, "Hello!", markup)
+	require.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	assert.Equal(t, "Hello!", msg.Text)
+	assert.Equal(t, int64(42), msg.Chat.ID)
+	assert.Equal(t, "Yes", msg.ReplyMarkup.InlineKeyboard[0][0].Text)
+
+	call, ok := srv.Last("sendMessage")
+	require.True(t, ok)
+	assert.Equal(t, "42", call.Params["chat_id"])
+	assert.Contains(t, call.Params["reply_markup"], `"callback_data":"\fyes"`)
+
+	doc := &tele.Document{File: tele.FromReader(strings.NewReader("data")), FileName: "file.txt"}
+	msg, err = b.Send(chat, doc)
+	require.NoError(t, err)
+	assert.NotEmpty(t, msg.Document.FileID)
+
+	call, _ = srv.Last("sendDocument")
+	assert.Equal(t, File{Name: "file.txt", Data: []byte("data")}, call.Files["document"])
+
+	var buf bytes.Buffer
+	require.NoError(t, func() error {
+		r, err := b.File(&msg.Document.File)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		_, err = io.Copy(&buf, r)
+		return err
+	}())
+	assert.Equal(t, "data", buf.String())
+
+	msgs, err := b.SendAlbum(chat, tele.Album{
+		&tele.Photo{File: tele.FromReader(strings.NewReader("1"))},
+		&tele.Photo{File: tele.FromReader(strings.NewReader("2"))},
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, msgs[0].AlbumID, msgs[1].AlbumID)
+	assert.NotEqual(t, msgs[0].Photo.FileID, msgs[1].Photo.FileID)
+
+	msg, err = b.Edit(msg, "Edited")
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", msg.Text)
+
+	assert.Len(t, srv.Calls(), 6)
+	assert.Len(t, srv.Calls("sendMessage", "editMessageText"), 2)
+}
+
+func TestServerFail(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	b, err := srv.NewBot(tele.Settings{})
+	require.NoError(t, err)
+
+	srv.Fail("sendMessage",
+		tele.ErrBlockedByUser,
+		tele.FloodError{RetryAfter: 5},
+		tele.GroupError{MigratedTo: -100},
+		errors.New("custom"),
+	)
+
+	_, err = b.Send(&tele.Chat{ID: 1}, "text")
+	assert.Equal(t, tele.ErrBlockedByUser, err)
+
+	_, err = b.Send(&tele.Chat{ID: 1}, "text")
+	var flood tele.FloodError
+	require.ErrorAs(t, err, &flood)
+	assert.Equal(t, 5, flood.RetryAfter)
+
+	_, err = b.Send(&tele.Chat{ID: 1}, "text")
+	var group tele.GroupError
+	require.ErrorAs(t, err, &group)
+	assert.Equal(t, int64(-100), group.MigratedTo)
+
+	_, err = b.Send(&tele.Chat{ID: 1}, "text")
+	assert.EqualError(t, err, "telegram: Bad Request: custom (400)")
+
+	_, err = b.Send(&tele.Chat{ID: 1}, "text")
+	assert.NoError(t, err)
+}
+
+func TestServerUpdates(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	b, err := srv.NewBot(tele.Settings{Synchronous: true})
+	require.NoError(t, err)
+
+	got := make(chan string, 2)
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		got <- c.Text()
+		return c.Send("pong")
+	})
+
+	go b.Start()
+	defer b.Stop()
+
+	require.NoError(t, srv.Push(
+		tele.Update{Message: &tele.Message{Chat: &tele.Chat{ID: 1}, Text: "first"}},
+		tele.Update{Message: &tele.Message{Chat: &tele.Chat{ID: 1}, Text: "second"}},
+	))
+	assert.Equal(t, "first", <-got)
+	assert.Equal(t, "second", <-got)
+
+	assert.Eventually(t, func() bool {
+		return len(srv.Calls("sendMessage")) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestServerWebhook(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	h := &tele.Webhook{
+		Listen:      "127.0.0.1:0",
+		SecretToken: "secret",
+	}
+	b, err := srv.NewBot(tele.Settings{Poller: h})
+	require.NoError(t, err)
+
+	got := make(chan string, 1)
+	b.Handle(tele.OnText, func(c tele.Context) error {
+		got <- c.Text()
+		return nil
+	})
+
+	// serving the webhook on the test server instead of the listener
+	hook := NewServer()
+	defer hook.Close()
+	hook.Config.Handler = h
+	h.Endpoint = &tele.WebhookEndpoint{PublicURL: hook.URL}
+	h.Listen = ""
+
+	go b.Start()
+	defer b.Stop()
+
+	assert.Eventually(t, func() bool {
+		_, ok := srv.Last("setWebhook")
+		return ok
+	}, time.Second, time.Millisecond)
+
+	call, _ := srv.Last("setWebhook")
+	assert.Equal(t, "secret", call.Params["secret_token"])
+
+	require.NoError(t, srv.Push(tele.Update{Message: &tele.Message{Chat: &tele.Chat{ID: 1}, Text: "hook"}}))
+	assert.Equal(t, "hook", <-got)
+}
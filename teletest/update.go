@@ -0,0 +1,335 @@
+package teletest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf16"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+var (
+	// DefaultUser is the sender of the built updates by default.
+	DefaultUser = &tele.User{
+		ID:           100,
+		FirstName:    "User",
+		Username:     "user",
+		LanguageCode: "en",
+	}
+
+	// DefaultGroup is the chat of the built join requests and chat
+	// member updates by default.
+	DefaultGroup = &tele.Chat{
+		ID:    -100,
+		Type:  tele.ChatSuperGroup,
+		Title: "Group",
+	}
+
+	// DefaultBot is the bot user of the built MyChatMember updates,
+	// the same as the one the Server introduces itself with.
+	DefaultBot = &tele.User{
+		ID:        1,
+		FirstName: "Bot",
+		Username:  "bot",
+		IsBot:     true,
+	}
+)
+
+// lastID is used to make up the IDs of the messages, callbacks,
+// queries etc, unique across the built updates.
+var lastID int64
+
+func nextID() int {
+	return int(atomic.AddInt64(&lastID, 1))
+}
+
+// entityRx matches the entities Telegram detects in the plain text.
+var entityRx = regexp.MustCompile(`(^|\s)(/\w+(@\w+)?|@\w{5,}|#\w+|\$[A-Z]{1,8})\b`)
+
+// UpdateBuilder builds the synthetic updates, looking like the ones
+// Telegram sends, so they are routed by the bot as the real traffic.
+// The builder is set up with the chainable methods, and the update
+// is built by the final one, e.g. Command or Callback.
+//
+// Example:
+//
+//		admin := &tele.User{ID: 42}
+//		group := &tele.Chat{ID: -1001, Type: tele.ChatSuperGroup}
+//
+//		b.ProcessUpdate(teletest.NewUpdate().
+//			From(admin).
+//			InChat(group).
+//			Command("/ban", "123"))
+//
+type UpdateBuilder struct {
+	id      int
+	sender  *tele.User
+	chat    *tele.Chat
+	replyTo *tele.Message
+	message *tele.Message
+}
+
+// NewUpdate returns the builder of the update sent by DefaultUser
+// in the private chat with the bot.
+func NewUpdate() *UpdateBuilder {
+	return &UpdateBuilder{sender: DefaultUser}
+}
+
+// ID sets the ID of the update. By default, it's zero,
+// so the Server assigns the next one on Push.
+func (b *UpdateBuilder) ID(id int) *UpdateBuilder {
+	b.id = id
+	return b
+}
+
+// From sets the user the update comes from.
+func (b *UpdateBuilder) From(user *tele.User) *UpdateBuilder {
+	b.sender = user
+	return b
+}
+
+// InChat sets the chat the update comes from. By default, it's
+// the private chat with the sender. The messages in the channels
+// are built as the channel posts.
+func (b *UpdateBuilder) InChat(chat *tele.Chat) *UpdateBuilder {
+	b.chat = chat
+	return b
+}
+
+// ReplyTo sets the message the built message replies to.
+func (b *UpdateBuilder) ReplyTo(msg *tele.Message) *UpdateBuilder {
+	b.replyTo = msg
+	return b
+}
+
+// OnMessage sets the message with the inline keyboard the built
+// callback comes from. By default, it's a message in the chat.
+func (b *UpdateBuilder) OnMessage(msg *tele.Message) *UpdateBuilder {
+	b.message = msg
+	return b
+}
+
+// Text builds the text message. The commands, mentions, hashtags
+// and cashtags are marked with the entities, as Telegram does.
+func (b *UpdateBuilder) Text(text string) tele.Update {
+	msg := b.newMessage()
+	msg.Text = text
+	msg.Entities = entities(text)
+	return b.Message(msg)
+}
+
+// Command builds the message with the command and its arguments,
+// separated by spaces, e.g. Command("/ban", "123").
+func (b *UpdateBuilder) Command(cmd string, args ...string) tele.Update {
+	if !strings.HasPrefix(cmd, "/") {
+		cmd = "/" + cmd
+	}
+	return b.Text(strings.Join(append([]string{cmd}, args...), " "))
+}
+
+// Message builds the update with the message, filling in its
+// missing ID, date, sender and chat. Use it to build the media,
+// service and other kinds of messages.
+func (b *UpdateBuilder) Message(msg *tele.Message) tele.Update {
+	if msg.ID == 0 {
+		msg.ID = nextID()
+	}
+	if msg.Unixtime == 0 {
+		msg.Unixtime = time.Now().Unix()
+	}
+	if msg.Chat == nil {
+		msg.Chat = b.currentChat()
+	}
+	if msg.ReplyTo == nil {
+		msg.ReplyTo = b.replyTo
+	}
+
+	if msg.Chat.Type == tele.ChatChannel {
+		msg.SenderChat = msg.Chat
+		return tele.Update{ID: b.id, ChannelPost: msg}
+	}
+	if msg.Sender == nil {
+		msg.Sender = b.sender
+	}
+	return tele.Update{ID: b.id, Message: msg}
+}
+
+// Callback builds the callback of the inline button with the unique
+// identifier and the data, joined with "|" as the Btn.Data does, so
+// it's routed to the handler of the button.
+func (b *UpdateBuilder) Callback(unique string, data ...string) tele.Update {
+	raw := "\f" + unique
+	if len(data) > 0 {
+		raw += "|" + strings.Join(data, "|")
+	}
+	return b.CallbackData(raw)
+}
+
+// CallbackData builds the callback with the raw data, e.g. the one
+// of the inline button without the unique identifier.
+func (b *UpdateBuilder) CallbackData(data string) tele.Update {
+	msg := b.message
+	if msg == nil {
+		msg = b.newMessage()
+		msg.Sender = DefaultBot
+	}
+
+	return tele.Update{ID: b.id, Callback: &tele.Callback{
+		ID:      strconv.Itoa(nextID()),
+		Sender:  b.sender,
+		Message: msg,
+		Data:    data,
+	}}
+}
+
+// Query builds the inline query with the text.
+func (b *UpdateBuilder) Query(text string) tele.Update {
+	chatType := "sender"
+	if b.chat != nil && b.chat.ID != b.sender.ID {
+		chatType = string(b.chat.Type)
+	}
+
+	return tele.Update{ID: b.id, Query: &tele.Query{
+		ID:       strconv.Itoa(nextID()),
+		Sender:   b.sender,
+		Text:     text,
+		ChatType: chatType,
+	}}
+}
+
+// ShippingQuery builds the shipping query of the invoice with the
+// payload, which is delivered to the given address.
+func (b *UpdateBuilder) ShippingQuery(payload string, address tele.ShippingAddress) tele.Update {
+	return tele.Update{ID: b.id, ShippingQuery: &tele.ShippingQuery{
+		ID:      strconv.Itoa(nextID()),
+		Sender:  b.sender,
+		Payload: payload,
+		Address: address,
+	}}
+}
+
+// PreCheckoutQuery builds the pre-checkout query of the invoice
+// with the payload, the total amount is in the smallest units of
+// the currency, e.g. cents.
+func (b *UpdateBuilder) PreCheckoutQuery(payload, currency string, total int) tele.Update {
+	return tele.Update{ID: b.id, PreCheckoutQuery: &tele.PreCheckoutQuery{
+		ID:       strconv.Itoa(nextID()),
+		Sender:   b.sender,
+		Payload:  payload,
+		Currency: currency,
+		Total:    total,
+	}}
+}
+
+// Payment builds the service message about the successful payment
+// of the invoice with the payload.
+func (b *UpdateBuilder) Payment(payload, currency string, total int) tele.Update {
+	msg := b.newMessage()
+	msg.Payment = &tele.Payment{
+		Payload:          payload,
+		Currency:         currency,
+		Total:            total,
+		TelegramChargeID: "telegram" + strconv.Itoa(msg.ID),
+		ProviderChargeID: "provider" + strconv.Itoa(msg.ID),
+	}
+	return b.Message(msg)
+}
+
+// JoinRequest builds the request of the sender to join the chat,
+// which is DefaultGroup by default.
+func (b *UpdateBuilder) JoinRequest() tele.Update {
+	return tele.Update{ID: b.id, ChatJoinRequest: &tele.ChatJoinRequest{
+		Chat:       b.groupChat(),
+		Sender:     b.sender,
+		UserChatID: b.sender.ID,
+		Unixtime:   time.Now().Unix(),
+	}}
+}
+
+// ChatMember builds the update of the sender's membership status
+// in the chat, which is DefaultGroup by default, e.g. ChatMember(
+// tele.Left, tele.Member) for the user joined the group.
+func (b *UpdateBuilder) ChatMember(old, new tele.MemberStatus) tele.Update {
+	u := b.memberUpdate(b.groupChat(), b.sender, old, new)
+	return tele.Update{ID: b.id, ChatMember: u}
+}
+
+// MyChatMember builds the update of DefaultBot's membership status
+// in the chat, changed by the sender, e.g. MyChatMember(tele.Member,
+// tele.Kicked) for the bot blocked by the user in the private chat.
+func (b *UpdateBuilder) MyChatMember(old, new tele.MemberStatus) tele.Update {
+	u := b.memberUpdate(b.currentChat(), DefaultBot, old, new)
+	return tele.Update{ID: b.id, MyChatMember: u}
+}
+
+func (b *UpdateBuilder) memberUpdate(chat *tele.Chat, user *tele.User, old, new tele.MemberStatus) *tele.ChatMemberUpdate {
+	return &tele.ChatMemberUpdate{
+		Chat:          chat,
+		Sender:        b.sender,
+		Unixtime:      time.Now().Unix(),
+		OldChatMember: &tele.ChatMember{User: user, Role: old},
+		NewChatMember: &tele.ChatMember{User: user, Role: new},
+	}
+}
+
+func (b *UpdateBuilder) newMessage() *tele.Message {
+	return &tele.Message{
+		ID:       nextID(),
+		Unixtime: time.Now().Unix(),
+		Chat:     b.currentChat(),
+	}
+}
+
+// currentChat returns the chat of the update, or the private chat
+// with the sender if there is none.
+func (b *UpdateBuilder) currentChat() *tele.Chat {
+	if b.chat != nil {
+		return b.chat
+	}
+	return &tele.Chat{
+		ID:        b.sender.ID,
+		Type:      tele.ChatPrivate,
+		FirstName: b.sender.FirstName,
+		LastName:  b.sender.LastName,
+		Username:  b.sender.Username,
+	}
+}
+
+func (b *UpdateBuilder) groupChat() *tele.Chat {
+	if b.chat != nil {
+		return b.chat
+	}
+	return DefaultGroup
+}
+
+// entities returns the entities found in the text, their offsets
+// and lengths are in UTF-16 code units as Telegram counts them.
+func entities(text string) tele.Entities {
+	var ents tele.Entities
+	for _, m := range entityRx.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[4], m[5]
+		ent := tele.MessageEntity{
+			Offset: utf16Len(text[:start]),
+			Length: utf16Len(text[start:end]),
+		}
+		switch text[start] {
+		case '/':
+			ent.Type = tele.EntityCommand
+		case '@':
+			ent.Type = tele.EntityMention
+		case '#':
+			ent.Type = tele.EntityHashtag
+		case '$':
+			ent.Type = tele.EntityCashtag
+		}
+		ents = append(ents, ent)
+	}
+	return ents
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,96 @@
+package teletest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+func TestUpdateBuilder(t *testing.T) {
+	b, err := tele.NewBot(tele.Settings{Offline: true, Synchronous: true})
+	require.NoError(t, err)
+
+	var got []string
+	route := func(name string) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			got = append(got, name+":"+c.Data())
+			return nil
+		}
+	}
+
+	btn := (&tele.ReplyMarkup{}).Data("Ban", "ban")
+	b.Handle("/ban", route("ban"))
+	b.Handle(&btn, route("btn"))
+	b.Handle(tele.OnText, route("text"))
+	b.Handle(tele.OnCallback, route("callback"))
+	b.Handle(tele.OnQuery, route("query"))
+	b.Handle(tele.OnShipping, route("shipping"))
+	b.Handle(tele.OnCheckout, route("checkout"))
+	b.Handle(tele.OnPayment, route("payment"))
+	b.Handle(tele.OnChatJoinRequest, route("join"))
+	b.Handle(tele.OnChatMember, route("member"))
+	b.Handle(tele.OnMyChatMember, route("my"))
+	b.Handle(tele.OnChannelPost, route("post"))
+
+	admin := &tele.User{ID: 42, FirstName: "Admin"}
+	group := &tele.Chat{ID: -1001, Type: tele.ChatSuperGroup}
+
+	u := NewUpdate().From(admin).InChat(group).Command("/ban", "123")
+	assert.Equal(t, admin, u.Message.Sender)
+	assert.Equal(t, group, u.Message.Chat)
+	assert.Equal(t, "/ban 123", u.Message.Text)
+	assert.Equal(t, tele.Entities{{Type: tele.EntityCommand, Length: 4}}, u.Message.Entities)
+	b.ProcessUpdate(u)
+
+	b.ProcessUpdate(NewUpdate().Text("hello"))
+	b.ProcessUpdate(NewUpdate().Callback("ban", "123", "spam"))
+	b.ProcessUpdate(NewUpdate().CallbackData("raw"))
+	b.ProcessUpdate(NewUpdate().Query("cats"))
+	b.ProcessUpdate(NewUpdate().ShippingQuery("order", tele.ShippingAddress{CountryCode: "UA"}))
+	b.ProcessUpdate(NewUpdate().PreCheckoutQuery("order", "USD", 100))
+	b.ProcessUpdate(NewUpdate().Payment("order", "USD", 100))
+	b.ProcessUpdate(NewUpdate().JoinRequest())
+	b.ProcessUpdate(NewUpdate().ChatMember(tele.Left, tele.Member))
+	b.ProcessUpdate(NewUpdate().MyChatMember(tele.Member, tele.Kicked))
+	b.ProcessUpdate(NewUpdate().InChat(&tele.Chat{ID: -1002, Type: tele.ChatChannel}).Text("news"))
+
+	assert.Equal(t, []string{
+		"ban:123",
+		"text:",
+		"btn:123|spam",
+		"callback:raw",
+		"query:cats",
+		"shipping:order",
+		"checkout:order",
+		"payment:order",
+		"join:",
+		"member:",
+		"my:",
+		"post:",
+	}, got)
+}
+
+func TestUpdateEntities(t *testing.T) {
+	u := NewUpdate().Text("привіт /start@bot #tag @someone $USD")
+	assert.Equal(t, tele.Entities{
+		{Type: tele.EntityCommand, Offset: 7, Length: 10},
+		{Type: tele.EntityHashtag, Offset: 18, Length: 4},
+		{Type: tele.EntityMention, Offset: 23, Length: 8},
+		{Type: tele.EntityCashtag, Offset: 32, Length: 4},
+	}, u.Message.Entities)
+
+	u = NewUpdate().Text("👍 /help")
+	assert.Equal(t, 3, u.Message.Entities[0].Offset)
+
+	u = NewUpdate().Command("help")
+	assert.Equal(t, "/help", u.Message.Text)
+	assert.Equal(t, int64(100), u.Message.Chat.ID)
+	assert.Equal(t, tele.ChatPrivate, u.Message.Chat.Type)
+
+	u = NewUpdate().MyChatMember(tele.Member, tele.Kicked)
+	assert.Equal(t, DefaultBot, u.MyChatMember.NewChatMember.User)
+	assert.Equal(t, tele.ChatPrivate, u.MyChatMember.Chat.Type)
+}
@@ -1,6 +1,9 @@
 package telebot
 
-import "strings"
+import (
+	"strings"
+	"sync"
+)
 
 // Update object represents an incoming update.
 type Update struct {
@@ -41,6 +44,15 @@ func (b *Bot) ProcessUpdate(u Update) {
 func (b *Bot) ProcessContext(c Context) {
 	u := c.Update()
 
+	b.tracker.add(u.ID)
+	defer b.tracker.done(u.ID)
+
+	// The answers to the questions asked with Ask
+	// are passed to the waiting handlers as is.
+	if b.askers.deliver(c) {
+		return
+	}
+
 	if u.Message != nil {
 		m := u.Message
 
@@ -80,11 +92,18 @@ func (b *Bot) ProcessContext(c Context) {
 				return
 			}
 
+			fallback := []string{OnText}
 			if m.ReplyTo != nil {
-				b.handle(OnReply, c)
+				fallback = []string{OnReply, OnText}
+			}
+
+			if b.handlePattern(c, m.Text, fallback...) {
+				return
 			}
 
-			b.handle(OnText, c)
+			for _, end := range fallback {
+				b.handle(end, c)
+			}
 			return
 		}
 
@@ -285,6 +304,10 @@ func (b *Bot) ProcessContext(c Context) {
 			}
 		}
 
+		if b.handlePattern(c, strings.TrimPrefix(u.Callback.Data, "\f"), OnCallback) {
+			return
+		}
+
 		b.handle(OnCallback, c)
 		return
 	}
@@ -401,14 +424,23 @@ func (b *Bot) handleMedia(c Context) bool {
 }
 
 func (b *Bot) runHandler(h HandlerFunc, c Context) {
+	id := c.Update().ID
+
+	b.running.add()
+	b.tracker.add(id)
 	f := func() {
+		defer b.running.done()
+		defer b.tracker.done(id)
 		if err := h(c); err != nil {
 			b.OnError(err, c)
 		}
 	}
-	if b.synchronous {
+	switch {
+	case b.synchronous:
 		f()
-	} else {
+	case b.workers != nil:
+		b.workers.run(c, f)
+	default:
 		go f()
 	}
 }
@@ -421,3 +453,84 @@ func isUserInList(user *User, list []User) bool {
 	}
 	return false
 }
+
+// updateTracker notifies the listeners once all the
+// handlers of an update are finished.
+type updateTracker struct {
+	mu        sync.Mutex
+	pending   map[int]int
+	listeners map[*func(id int)]struct{}
+}
+
+// listen adds the listener, it returns a function removing it.
+func (t *updateTracker) listen(f func(id int)) (cancel func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.listeners == nil {
+		t.pending = make(map[int]int)
+		t.listeners = make(map[*func(id int)]struct{})
+	}
+	t.listeners[&f] = struct{}{}
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.listeners, &f)
+	}
+}
+
+func (t *updateTracker) add(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.listeners) > 0 {
+		t.pending[id]++
+	}
+}
+
+// skip marks the update dropped before processing as done,
+// unless the update with the same ID is still in progress.
+func (t *updateTracker) skip(id int) {
+	t.mu.Lock()
+	if t.pending[id] > 0 {
+		t.mu.Unlock()
+		return
+	}
+
+	listeners := make([]func(int), 0, len(t.listeners))
+	for f := range t.listeners {
+		listeners = append(listeners, *f)
+	}
+	t.mu.Unlock()
+
+	for _, f := range listeners {
+		f(id)
+	}
+}
+
+func (t *updateTracker) done(id int) {
+	t.mu.Lock()
+	n, ok := t.pending[id]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+
+	if n > 1 {
+		t.pending[id] = n - 1
+		t.mu.Unlock()
+		return
+	}
+
+	delete(t.pending, id)
+	listeners := make([]func(int), 0, len(t.listeners))
+	for f := range t.listeners {
+		listeners = append(listeners, *f)
+	}
+	t.mu.Unlock()
+
+	for _, f := range listeners {
+		f(id)
+	}
+}
@@ -4,10 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 )
 
+// TelegramSubnets are the subnets Telegram sends webhook requests from.
+var TelegramSubnets = []string{
+	"149.154.160.0/20",
+	"91.108.4.0/22",
+}
+
 // A WebhookTLS specifies the path to a key and a cert so the poller can open
 // a TLS listener.
 type WebhookTLS struct {
@@ -59,8 +70,44 @@ type Webhook struct {
 	TLS      *WebhookTLS
 	Endpoint *WebhookEndpoint
 
-	dest chan<- Update
+	// Reply enables replying to the webhook requests with a Bot API
+	// method call, see WebhookReply option. Each request is held until
+	// the handlers of its update are finished, or ReplyTimeout expires.
+	Reply        bool          `json:"-"`
+	ReplyTimeout time.Duration `json:"-"` // Default: 10s
+
+	// MaxBodySize limits the size of the request body. Default: 1 MB.
+	MaxBodySize int64 `json:"-"`
+
+	// EnqueueTimeout limits the time to wait for the bot to take
+	// the update, if it's saturated. Default: 10s.
+	EnqueueTimeout time.Duration `json:"-"`
+
+	// AllowedIPs restricts the addresses of the requests, it contains
+	// either IPs or CIDR subnets. See TelegramSubnets.
+	// Notice the address is taken from the connection, so it won't work
+	// behind a reverse proxy.
+	AllowedIPs []string `json:"-"`
+
+	stats webhookStats
+
+	// The requests may be served before Poll is called,
+	// e.g. by WebhookServer, hence the mutex.
+	mu     sync.RWMutex
+	polled *webhookPoll
+}
+
+// webhookPoll is the bot polling the webhook.
+type webhookPoll struct {
 	bot  *Bot
+	dest chan<- Update
+	stop chan struct{}
+}
+
+func (h *Webhook) poll() *webhookPoll {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.polled
 }
 
 func (h *Webhook) getFiles() map[string]File {
@@ -121,21 +168,32 @@ func (h *Webhook) getParams() map[string]string {
 }
 
 func (h *Webhook) Poll(b *Bot, dest chan Update, stop chan struct{}) {
+	// store the variables so the HTTP-handler can use 'em
+	p := &webhookPoll{bot: b, dest: dest, stop: stop}
+	h.mu.Lock()
+	h.polled = p
+	h.mu.Unlock()
+
+	// the requests coming after the bot is stopped are
+	// rejected, so Telegram delivers the updates again
+	defer func() {
+		h.mu.Lock()
+		if h.polled == p {
+			h.polled = nil
+		}
+		h.mu.Unlock()
+	}()
+
 	// by default, the set webhook method will be called, to ignore it, set IgnoreSetWebhook to true
 	if !h.IgnoreSetWebhook {
 		if err := b.SetWebhook(h); err != nil {
 			b.OnError(err, nil)
-			close(stop)
 			return
 		}
 	}
 
-	// store the variables so the HTTP-handler can use 'em
-	h.dest = dest
-	h.bot = b
-
 	if h.Listen == "" {
-		h.waitForStop(stop)
+		<-stop
 		return
 	}
 
@@ -144,37 +202,312 @@ func (h *Webhook) Poll(b *Bot, dest chan Update, stop chan struct{}) {
 		Handler: h,
 	}
 
-	go func(stop chan struct{}) {
-		h.waitForStop(stop)
-		s.Shutdown(context.Background())
-	}(stop)
+	// Shutdown lets the in-flight requests finish, so the poller
+	// stays alive until it returns, or the bot cancels the pending
+	// requests, see Bot.Shutdown.
+	stopped := b.stopClient.done()
+	shutdown := make(chan struct{})
+	go func() {
+		<-stop
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-stopped:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		s.Shutdown(ctx)
+		close(shutdown)
+	}()
 
+	var err error
 	if h.TLS != nil {
-		s.ListenAndServeTLS(h.TLS.Cert, h.TLS.Key)
+		err = s.ListenAndServeTLS(h.TLS.Cert, h.TLS.Key)
 	} else {
-		s.ListenAndServe()
+		err = s.ListenAndServe()
 	}
-}
 
-func (h *Webhook) waitForStop(stop chan struct{}) {
-	<-stop
-	close(stop)
+	if err != http.ErrServerClosed {
+		b.OnError(err, nil)
+		return
+	}
+	<-shutdown
 }
 
 // The handler simply reads the update from the body of the requests
 // and writes them to the update channel.
+//
+// Rejected requests are answered with the corresponding status code:
+// 405 for non-POST requests, 403 for the ones from not allowed IPs,
+// 401 for an invalid secret token, 413 for too large and 400 for
+// malformed bodies, and 503 if the update can't be queued in time.
+// See Stats for the number of rejected requests.
 func (h *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := h.poll()
+	if p == nil {
+		// not polled by any bot yet
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		h.reject(w, p.bot, http.StatusMethodNotAllowed, &h.stats.BadMethod,
+			fmt.Errorf("unexpected %s request", r.Method))
+		return
+	}
+
+	if len(h.AllowedIPs) > 0 && !h.allowedIP(r.RemoteAddr) {
+		h.reject(w, p.bot, http.StatusForbidden, &h.stats.BadIP,
+			fmt.Errorf("request from not allowed address %s", r.RemoteAddr))
+		return
+	}
+
 	if h.SecretToken != "" && r.Header.Get("X-Telegram-Bot-Api-Secret-Token") != h.SecretToken {
-		h.bot.debug(fmt.Errorf("invalid secret token in request"))
+		h.reject(w, p.bot, http.StatusUnauthorized, &h.stats.BadSecret,
+			fmt.Errorf("invalid secret token in request"))
+		return
+	}
+
+	limit := h.MaxBodySize
+	if limit <= 0 {
+		limit = 1 << 20
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		h.reject(w, p.bot, http.StatusBadRequest, &h.stats.BadPayload,
+			fmt.Errorf("cannot read update: %v", err))
+		return
+	}
+	if int64(len(data)) > limit {
+		h.reject(w, p.bot, http.StatusRequestEntityTooLarge, &h.stats.TooLarge,
+			fmt.Errorf("update exceeds %d bytes", limit))
 		return
 	}
 
 	var update Update
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
-		h.bot.debug(fmt.Errorf("cannot decode update: %v", err))
+	if err := json.Unmarshal(data, &update); err != nil {
+		h.reject(w, p.bot, http.StatusBadRequest, &h.stats.BadPayload,
+			fmt.Errorf("cannot decode update: %v", err))
 		return
 	}
-	h.dest <- update
+
+	if h.Reply {
+		h.serveReply(w, p, update)
+		return
+	}
+
+	if !h.enqueue(p, update) {
+		h.reject(w, p.bot, http.StatusServiceUnavailable, &h.stats.Saturated,
+			fmt.Errorf("cannot queue update %d", update.ID))
+	}
+}
+
+// enqueue passes the update to the bot. It returns false if the bot
+// is stopped or the update isn't taken before EnqueueTimeout expires.
+// In this case, Telegram will deliver the update again later.
+func (h *Webhook) enqueue(p *webhookPoll, update Update) bool {
+	timeout := h.EnqueueTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	// select picks a random ready case, so the update
+	// could be queued to the stopped bot otherwise
+	select {
+	case <-p.stop:
+		return false
+	default:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case p.dest <- update:
+		return true
+	case <-p.stop:
+		return false
+	case <-timer.C:
+		return false
+	}
+}
+
+func (h *Webhook) reject(w http.ResponseWriter, b *Bot, code int, counter *uint64, err error) {
+	h.stats.mu.Lock()
+	*counter++
+	h.stats.mu.Unlock()
+
+	if code == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", http.MethodPost)
+	}
+	w.WriteHeader(code)
+	b.debug(err)
+}
+
+// allowedIP tells whether the remote address is in AllowedIPs.
+func (h *Webhook) allowedIP(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range h.AllowedIPs {
+		if _, subnet, err := net.ParseCIDR(cidr); err == nil {
+			if subnet.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(cidr); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookStats holds the number of rejected webhook requests by the reason.
+type WebhookStats struct {
+	BadMethod  uint64
+	BadIP      uint64
+	BadSecret  uint64
+	BadPayload uint64
+	TooLarge   uint64
+	Saturated  uint64
+}
+
+type webhookStats struct {
+	mu sync.Mutex
+	WebhookStats
+}
+
+// Stats returns the counters of rejected requests.
+func (h *Webhook) Stats() WebhookStats {
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+	return h.stats.WebhookStats
+}
+
+// serveReply passes the update to the bot and holds the request
+// until it gets a reply, the handlers are finished or it times out.
+func (h *Webhook) serveReply(w http.ResponseWriter, p *webhookPoll, update Update) {
+	b := p.bot
+
+	var (
+		once     sync.Once
+		finished = make(chan struct{})
+	)
+	defer b.tracker.listen(func(id int) {
+		if id == update.ID {
+			once.Do(func() { close(finished) })
+		}
+	})()
+
+	reply := b.replies.hold(update.ID)
+
+	if !h.enqueue(p, update) {
+		b.replies.release(update.ID)
+		h.reject(w, p.bot, http.StatusServiceUnavailable, &h.stats.Saturated,
+			fmt.Errorf("cannot queue update %d", update.ID))
+		return
+	}
+
+	timeout := h.ReplyTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var data []byte
+	select {
+	case data = <-reply:
+	case <-finished:
+	case <-timer.C:
+	}
+
+	// The reply is already taken by the handler,
+	// so it's going to be written right away.
+	if data == nil && !b.replies.release(update.ID) {
+		data = <-reply
+	}
+
+	if data != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+// webhookReplies holds the webhook requests waiting for a reply.
+type webhookReplies struct {
+	mu      sync.Mutex
+	waiting map[int]chan []byte
+}
+
+func (r *webhookReplies) hold(id int) <-chan []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.waiting == nil {
+		r.waiting = make(map[int]chan []byte)
+	}
+
+	ch := make(chan []byte, 1)
+	r.waiting[id] = ch
+	return ch
+}
+
+// release stops waiting for the reply. It returns false
+// if the reply is already taken.
+func (r *webhookReplies) release(id int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.waiting[id]; !ok {
+		return false
+	}
+	delete(r.waiting, id)
+	return true
+}
+
+// reply writes the method call to the request waiting for the update.
+// It returns false if there is no such request or the reply is
+// already taken, so the call should be made as usual.
+func (r *webhookReplies) reply(id int, method string, payload interface{}) ([]byte, bool) {
+	r.mu.Lock()
+	ch, ok := r.waiting[id]
+	delete(r.waiting, id)
+	r.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	body := make(map[string]json.RawMessage)
+	if data, err := json.Marshal(payload); err == nil {
+		json.Unmarshal(data, &body)
+	}
+	body["method"], _ = json.Marshal(method)
+
+	data, _ := json.Marshal(body)
+	ch <- data
+
+	return []byte(`{"ok":true}`), true
+}
+
+// webhookReplier returns a copy of the bot, which makes its first
+// request as a reply to the webhook request of the update.
+func (b *Bot) webhookReplier(updateID int) *Bot {
+	b2 := *b
+	b2.replyTo = &updateID
+	return &b2
 }
 
 // Webhook returns the current webhook status.
@@ -0,0 +1,145 @@
+package telebot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// A WebhookServer serves the webhooks of multiple bots on a single
+// listener. Requests to the PublicURL path followed by the bot name
+// are routed to the corresponding webhook. Bots can be added and
+// removed at any time without restarting the server.
+//
+// Example:
+//
+//	srv := &tele.WebhookServer{
+//		Listen:    ":8443",
+//		PublicURL: "https://example.com/bots",
+//	}
+//
+//	for name, b := range bots {
+//		// receives the updates from https://example.com/bots/<name>
+//		b.Poller = srv.Webhook(name, &tele.Webhook{})
+//		go b.Start()
+//	}
+//
+//	log.Fatal(srv.ListenAndServe())
+type WebhookServer struct {
+	// Listen is the address to listen on.
+	Listen string
+
+	// PublicURL is the base URL the server is reachable
+	// by Telegram at, e.g. https://example.com/bots.
+	PublicURL string
+
+	// TLS is used to open a secure listener, if set.
+	TLS *WebhookTLS
+
+	mu     sync.RWMutex
+	hooks  map[string]*Webhook
+	server *http.Server
+}
+
+// Webhook sets up the webhook to be served under the given name and
+// returns it, so it can be used as a bot poller. Its endpoint is set
+// to PublicURL/name, which is registered with SetWebhook once the bot
+// is started. If the webhook has no secret token, a random one is
+// generated. The webhook previously added with the same name is replaced.
+func (s *WebhookServer) Webhook(name string, h *Webhook) *Webhook {
+	h.Listen = ""
+	if h.Endpoint == nil {
+		h.Endpoint = &WebhookEndpoint{}
+	}
+	h.Endpoint.PublicURL = strings.TrimSuffix(s.PublicURL, "/") + "/" + name
+
+	if h.SecretToken == "" {
+		h.SecretToken = newSecretToken()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.hooks == nil {
+		s.hooks = make(map[string]*Webhook)
+	}
+	s.hooks[s.path(name)] = h
+	return h
+}
+
+// Remove stops routing the requests to the webhook with the given
+// name. Notice it doesn't stop the bot nor remove its webhook.
+func (s *WebhookServer) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.hooks, s.path(name))
+}
+
+// path returns the request path of the webhook.
+func (s *WebhookServer) path(name string) string {
+	base := ""
+	if u, err := url.Parse(s.PublicURL); err == nil {
+		base = strings.TrimSuffix(u.Path, "/")
+	}
+	return base + "/" + name
+}
+
+// ServeHTTP routes the request to the webhook by its path.
+func (s *WebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	h, ok := s.hooks[r.URL.Path]
+	s.mu.RUnlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts the server, it blocks until it's shut down.
+func (s *WebhookServer) ListenAndServe() error {
+	s.mu.Lock()
+	s.server = &http.Server{
+		Addr:    s.Listen,
+		Handler: s,
+	}
+	srv := s.server
+	s.mu.Unlock()
+
+	var err error
+	if s.TLS != nil {
+		err = srv.ListenAndServeTLS(s.TLS.Cert, s.TLS.Key)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully shuts the server down, letting the in-flight
+// requests finish. See http.Server.Shutdown.
+func (s *WebhookServer) Shutdown(ctx context.Context) error {
+	s.mu.RLock()
+	srv := s.server
+	s.mu.RUnlock()
+
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+func newSecretToken() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic("telebot: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,282 @@
+package telebot
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhook(t *testing.T, h *Webhook) *Bot {
+	b, err := NewBot(Settings{Offline: true, Poller: h})
+	require.NoError(t, err)
+
+	h.polled = &webhookPoll{bot: b, dest: b.Updates, stop: make(chan struct{})}
+
+	go func() {
+		for u := range b.Updates {
+			b.ProcessUpdate(u)
+		}
+	}()
+
+	return b
+}
+
+func TestWebhookReply(t *testing.T) {
+	h := &Webhook{Reply: true, ReplyTimeout: time.Second}
+	b := newTestWebhook(t, h)
+	defer close(b.Updates)
+
+	b.Handle("/start", func(c Context) error {
+		return c.Send("Hello!", WebhookReply)
+	})
+	// the files on disk are sent by their paths in the local mode
+	b.local = true
+	photo := filepath.Join(t.TempDir(), "photo.jpg")
+	require.NoError(t, ioutil.WriteFile(photo, []byte("photo"), 0600))
+
+	sent := make(chan error, 1)
+	b.Handle("/photo", func(c Context) error {
+		err := c.Send(&Photo{File: FromURL("https://example.com/photo.jpg")}, WebhookReply)
+		sent <- err
+		return err
+	})
+	b.Handle("/album", func(c Context) error {
+		err := c.SendAlbum(Album{
+			&Photo{File: FromDisk(photo)},
+			&Photo{File: FromDisk(photo)},
+		}, WebhookReply)
+		sent <- err
+		return err
+	})
+	b.Handle(OnText, func(c Context) error {
+		return nil
+	})
+
+	serve := func(text string) *httptest.ResponseRecorder {
+		body := `{"update_id":1,"message":{"chat":{"id":42},"text":"` + text + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/start")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var reply map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reply))
+	assert.Equal(t, "sendMessage", reply["method"])
+	assert.Equal(t, "42", reply["chat_id"])
+	assert.Equal(t, "Hello!", reply["text"])
+
+	w = serve("/photo")
+	require.NoError(t, <-sent)
+
+	reply = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reply))
+	assert.Equal(t, "sendPhoto", reply["method"])
+	assert.Equal(t, "https://example.com/photo.jpg", reply["photo"])
+
+	w = serve("/album")
+	require.NoError(t, <-sent)
+
+	reply = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reply))
+	assert.Equal(t, "sendMediaGroup", reply["method"])
+	assert.Contains(t, reply["media"], "file://")
+
+	start := time.Now()
+	w = serve("text")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestWebhookServer(t *testing.T) {
+	srv := &WebhookServer{PublicURL: "https://example.com/bots/"}
+
+	h1 := srv.Webhook("first", &Webhook{})
+	h2 := srv.Webhook("second", &Webhook{SecretToken: "secret"})
+	assert.Equal(t, "https://example.com/bots/first", h1.Endpoint.PublicURL)
+	assert.Len(t, h1.SecretToken, 64)
+	assert.Equal(t, "secret", h2.SecretToken)
+
+	// not started yet
+	req := httptest.NewRequest(http.MethodPost, "/bots/first", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	b1, b2 := newTestWebhook(t, h1), newTestWebhook(t, h2)
+	defer close(b1.Updates)
+	defer close(b2.Updates)
+
+	got := make(chan string, 2)
+	b1.Handle(OnText, func(c Context) error { got <- "first"; return nil })
+	b2.Handle(OnText, func(c Context) error { got <- "second"; return nil })
+
+	serve := func(path, token string) int {
+		body := `{"update_id":1,"message":{"chat":{"id":42},"text":"text"}}`
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/bots/second", "secret"))
+	assert.Equal(t, "second", <-got)
+	assert.Equal(t, http.StatusOK, serve("/bots/first", h1.SecretToken))
+	assert.Equal(t, "first", <-got)
+
+	assert.Equal(t, http.StatusNotFound, serve("/bots/third", ""))
+	srv.Remove("first")
+	assert.Equal(t, http.StatusNotFound, serve("/bots/first", h1.SecretToken))
+}
+
+func TestWebhookServerStart(t *testing.T) {
+	srv := &WebhookServer{PublicURL: "https://example.com/bots/"}
+	h := srv.Webhook("bot", &Webhook{IgnoreSetWebhook: true})
+
+	b, err := NewBot(Settings{Offline: true, Poller: h})
+	require.NoError(t, err)
+
+	got := make(chan struct{}, 1)
+	b.Handle(OnText, func(c Context) error {
+		select {
+		case got <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	serve := func() int {
+		body := `{"update_id":1,"message":{"chat":{"id":42},"text":"text"}}`
+		req := httptest.NewRequest(http.MethodPost, "/bots/bot", strings.NewReader(body))
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", h.SecretToken)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// the requests are served while the bot is starting
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for serve() != http.StatusOK {
+		}
+	}()
+
+	go b.Start()
+
+	<-done
+	<-got
+
+	// the updates are not taken by the stopped bot
+	b.Stop()
+	for i := 0; i < 100; i++ {
+		require.Equal(t, http.StatusServiceUnavailable, serve())
+	}
+}
+
+func TestWebhookServeHTTP(t *testing.T) {
+	h := &Webhook{
+		SecretToken:    "secret",
+		MaxBodySize:    100,
+		EnqueueTimeout: 10 * time.Millisecond,
+		AllowedIPs:     append([]string{"10.0.0.1"}, TelegramSubnets...),
+	}
+
+	b, err := NewBot(Settings{Offline: true, Updates: 1})
+	require.NoError(t, err)
+	h.polled = &webhookPoll{bot: b, dest: b.Updates, stop: make(chan struct{})}
+
+	serve := func(method, addr, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.RemoteAddr = addr
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	const (
+		telegram = "149.154.167.220:443"
+		update   = `{"update_id":1}`
+	)
+
+	w := serve(http.MethodGet, telegram, "secret", update)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "1.1.1.1:443", "secret", update).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, telegram, "bad", update).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, telegram, "secret", "{").Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(http.MethodPost, telegram, "secret", strings.Repeat(" ", 101)).Code)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "10.0.0.1:443", "secret", update).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, telegram, "secret", update).Code)
+	assert.Equal(t, 1, (<-b.Updates).ID)
+
+	assert.Equal(t, WebhookStats{
+		BadMethod:  1,
+		BadIP:      1,
+		BadSecret:  1,
+		BadPayload: 1,
+		TooLarge:   1,
+		Saturated:  1,
+	}, h.Stats())
+}
+
+func TestWebhookShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	h := &Webhook{Listen: addr, IgnoreSetWebhook: true, Reply: true, ReplyTimeout: 5 * time.Second}
+	b, err := NewBot(Settings{Offline: true, Poller: h})
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	b.Handle(OnText, func(c Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	go b.Start()
+
+	body := `{"update_id":1,"message":{"chat":{"id":42},"text":"text"}}`
+	go func() {
+		for {
+			resp, err := http.Post("http://"+addr, "application/json", strings.NewReader(body))
+			if err == nil {
+				resp.Body.Close()
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// the request held for the reply doesn't keep the bot running
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, b.Shutdown(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
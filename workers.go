@@ -0,0 +1,127 @@
+package telebot
+
+import (
+	"runtime"
+	"sync"
+)
+
+// WorkerPool is a fixed-size pool of goroutines running the handlers.
+// Updates with the same key (by default, of the same chat) are always
+// handled by the same worker, so they are processed in order, while
+// the updates of different chats run in parallel.
+//
+// Once the worker queue is full, ProcessUpdate blocks until there is
+// a room, which in turn stops the bot from consuming Bot.Updates.
+//
+// Example:
+//
+//	pool := &tele.WorkerPool{Size: 16}
+//
+//	b, err := tele.NewBot(tele.Settings{
+//		Token:   "...",
+//		Workers: pool,
+//	})
+//
+//	// ...
+//	log.Println("handlers running:", pool.InFlight())
+type WorkerPool struct {
+	// Size is the number of workers. Default: runtime.NumCPU().
+	Size int
+
+	// Queue is the capacity of each worker queue. Default: 16.
+	Queue int
+
+	// Key returns the key updates are ordered by.
+	// Default: chat ID, falling back to the sender ID.
+	Key func(Context) int64
+
+	once     sync.Once
+	queues   []chan func()
+	mu       sync.Mutex
+	inFlight int
+	pending  int
+}
+
+// KeyBySender is a WorkerPool key function, which keeps
+// the updates of each user in order.
+func KeyBySender(c Context) int64 {
+	if sender := c.Sender(); sender != nil {
+		return sender.ID
+	}
+	return 0
+}
+
+// KeyByChat is the default WorkerPool key function, which keeps
+// the updates of each chat in order.
+func KeyByChat(c Context) int64 {
+	if chat := c.Chat(); chat != nil {
+		return chat.ID
+	}
+	return KeyBySender(c)
+}
+
+// InFlight returns the number of handlers running at the moment.
+func (p *WorkerPool) InFlight() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.inFlight
+}
+
+// Pending returns the number of handlers waiting in the queues.
+func (p *WorkerPool) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pending
+}
+
+func (p *WorkerPool) start() {
+	size := p.Size
+	if size < 1 {
+		size = runtime.NumCPU()
+	}
+	queue := p.Queue
+	if queue < 1 {
+		queue = 16
+	}
+
+	p.queues = make([]chan func(), size)
+	for i := range p.queues {
+		p.queues[i] = make(chan func(), queue)
+		go p.work(p.queues[i])
+	}
+}
+
+func (p *WorkerPool) work(queue chan func()) {
+	for f := range queue {
+		p.mu.Lock()
+		p.pending--
+		p.inFlight++
+		p.mu.Unlock()
+
+		f()
+
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+	}
+}
+
+// run enqueues f to the worker chosen by the context key.
+// It blocks while the worker queue is full.
+func (p *WorkerPool) run(c Context, f func()) {
+	p.once.Do(p.start)
+
+	key := KeyByChat
+	if p.Key != nil {
+		key = p.Key
+	}
+
+	k := uint64(key(c))
+	queue := p.queues[k%uint64(len(p.queues))]
+
+	p.mu.Lock()
+	p.pending++
+	p.mu.Unlock()
+
+	queue <- f
+}
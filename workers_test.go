@@ -0,0 +1,72 @@
+package telebot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := &WorkerPool{Size: 4, Queue: 1}
+
+	b, err := NewBot(Settings{Offline: true, Workers: pool})
+	require.NoError(t, err)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[int64][]int)
+	)
+
+	b.Handle(OnText, func(c Context) error {
+		defer wg.Done()
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		seen[c.Chat().ID] = append(seen[c.Chat().ID], c.Update().ID)
+		return nil
+	})
+
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		b.ProcessUpdate(Update{
+			ID: i,
+			Message: &Message{
+				Chat: &Chat{ID: int64(i % 3)},
+				Text: "text",
+			},
+		})
+	}
+	wg.Wait()
+
+	for chat, ids := range seen {
+		assert.Len(t, ids, 20)
+		for i, id := range ids {
+			assert.Equal(t, int(chat)+i*3, id)
+		}
+	}
+
+	assert.Zero(t, pool.Pending())
+	assert.Eventually(t, func() bool {
+		return pool.InFlight() == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestWorkerPoolKey(t *testing.T) {
+	c := NewContext(nil, Update{Message: &Message{
+		Sender: &User{ID: 1},
+		Chat:   &Chat{ID: 2},
+	}})
+	assert.Equal(t, int64(2), KeyByChat(c))
+	assert.Equal(t, int64(1), KeyBySender(c))
+
+	c = NewContext(nil, Update{Query: &Query{Sender: &User{ID: 1}}})
+	assert.Equal(t, int64(1), KeyByChat(c))
+
+	c = NewContext(nil, Update{})
+	assert.Zero(t, KeyByChat(c))
+}